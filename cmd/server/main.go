@@ -2,29 +2,39 @@ package main
 
 import (
 	"context"
+	"crypto/tls"
+	"errors"
 	"fmt"
 	"log"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"sync/atomic"
 	"syscall"
 	"time"
 
 	"github.com/gorilla/mux"
 
+	"merge-queue/internal/certs"
 	"merge-queue/internal/config"
 	"merge-queue/internal/handlers"
 	"merge-queue/internal/middleware"
+	"merge-queue/internal/models"
 	"merge-queue/internal/services"
+	"merge-queue/internal/storage"
 	"merge-queue/pkg/utils"
 )
 
 func main() {
+	bootStart := time.Now()
+
 	// Load configuration.
 	cfg, err := config.LoadConfig("config.json")
 	if err != nil {
 		log.Fatalf("Failed to load configuration: %v", err)
 	}
+	configDuration := time.Since(bootStart)
 
 	// Initialize logger.
 	logLevel := utils.InfoLevel
@@ -32,52 +42,182 @@ func main() {
 		logLevel = utils.DebugLevel
 	}
 	logger := utils.NewLogger(logLevel)
+	logger.SetFormat(utils.LogFormatFromString(cfg.App.LogFormat))
+	lifecycle := logger.With("component", "lifecycle")
+
+	lifecycle.With("event", "config_loaded").With("duration_ms", configDuration.Milliseconds()).
+		Info("Config loaded in %v", configDuration)
 
 	logger.Info("Starting %s v%s", cfg.App.Name, cfg.App.Version)
 	logger.Info("Environment: %s", cfg.App.Environment)
+	if cfg.App.Banner != "" {
+		logger.Info("📢 %s", cfg.App.Banner)
+	}
+	logger.Info("Effective configuration:\n%s", cfg.Summary())
+
+	models.SetValidRoles(cfg.Auth.Roles)
+	if len(cfg.Features.StatusTransitions) > 0 {
+		models.SetStatusTransitions(cfg.Features.StatusTransitions)
+	}
+
+	// Return the causes of 500s to clients only while debugging locally.
+	utils.SetExposeErrorDetails(cfg.App.Debug && cfg.App.Environment != "production")
 
 	// Initialize services.
-	taskService := services.NewTaskService(cfg.Features.MaxTasksPerUser)
+	servicesStart := time.Now()
+	var taskService *services.TaskService
+	if cfg.Storage.Path != "" {
+		key, err := cfg.Storage.Key()
+		if err != nil {
+			logger.Error("Invalid task store key: %v", err)
+			os.Exit(1)
+		}
+		store, err := storage.NewEncryptedJSONFileStore(cfg.Storage.Path, key)
+		if err != nil {
+			logger.Error("Failed to open task store: %v", err)
+			os.Exit(1)
+		}
+		taskService, err = services.NewTaskServiceWithStore(cfg, store)
+		if err != nil {
+			logger.Error("Failed to load tasks: %v", err)
+			os.Exit(1)
+		}
+		used, _ := taskService.Capacity()
+		logger.Info("Loaded %d tasks from %s", used, cfg.Storage.Path)
+	} else {
+		taskService = services.NewTaskService(cfg)
+	}
+	for _, seedErr := range taskService.SeedErrors() {
+		logger.Warn("Sample data: %v", seedErr)
+	}
 
 	// Initialize handlers.
 	taskHandler := handlers.NewTaskHandler(taskService, logger)
-	healthHandler := handlers.NewHealthHandler(cfg, logger)
+	healthHandler := handlers.NewHealthHandler(cfg, taskService, logger)
 	staticHandler := handlers.NewStaticHandler(cfg, logger)
 
 	// Initialize middleware.
-	corsMiddleware := middleware.NewCORSMiddleware(cfg)
+	recoveryMiddleware := middleware.NewRecoveryMiddleware(logger)
+	corsHandler := middleware.NewCORSMiddleware(cfg).Handler
+	var corsOrigins *middleware.ConfigurableCORSMiddleware
+	if cfg.Features.EnableCORS && cfg.Features.CORSOriginsFile != "" {
+		corsOrigins, err = middleware.NewFileCORSMiddleware(cfg, logger)
+		if err != nil {
+			logger.Error("Failed to load CORS origins: %v", err)
+			os.Exit(1)
+		}
+		go corsOrigins.Watch()
+		corsHandler = corsOrigins.Handler
+	}
 	loggingMiddleware := middleware.NewLoggingMiddleware(cfg, logger)
-	authMiddleware := middleware.NewAuthMiddleware(logger)
+	if cfg.Features.AccessLogFile != "" {
+		accessLog, err := os.OpenFile(cfg.Features.AccessLogFile, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+		if err != nil {
+			logger.Error("Failed to open access log: %v", err)
+			os.Exit(1)
+		}
+		defer accessLog.Close()
+		loggingMiddleware.SetAccessLog(accessLog)
+	}
+	compressionMiddleware := middleware.NewCompressionMiddleware(cfg)
+	metricsMiddleware := middleware.NewMetricsMiddleware(cfg)
+	authMiddleware := middleware.NewAuthMiddleware(cfg, logger)
+	requireAuthMiddleware := middleware.NewRequireAuthMiddleware(cfg, logger)
+	adminRoleMiddleware := middleware.NewRoleMiddleware("admin", logger)
 	rateLimitMiddleware := middleware.NewRateLimitMiddleware(cfg, logger)
+	concurrencyMiddleware := middleware.NewConcurrencyMiddleware(cfg, logger)
+	contentTypeMiddleware := middleware.NewContentTypeMiddleware(cfg, logger)
+
+	// Initialize admin handlers.
+	adminHandler := handlers.NewAdminHandler(taskService, rateLimitMiddleware, metricsMiddleware, concurrencyMiddleware, logger)
 
 	// Setup router.
 	router := setupRouter(
+		cfg,
+		logger,
 		taskHandler,
 		healthHandler,
 		staticHandler,
-		corsMiddleware,
+		adminHandler,
+		recoveryMiddleware,
+		corsHandler,
 		loggingMiddleware,
+		metricsMiddleware,
+		compressionMiddleware,
 		authMiddleware,
+		requireAuthMiddleware,
+		adminRoleMiddleware,
 		rateLimitMiddleware,
+		concurrencyMiddleware,
+		contentTypeMiddleware,
 	)
 
-	// Create HTTP server.
+	servicesDuration := time.Since(servicesStart)
+	lifecycle.With("event", "services_initialized").With("duration_ms", servicesDuration.Milliseconds()).
+		Info("Services initialized in %v", servicesDuration)
+
+	// Create HTTP server, tracking open connections so shutdown can report
+	// how many were drained.
+	var openConns int64
 	server := &http.Server{
-		Addr:         cfg.Server.Port,
-		Handler:      router,
-		ReadTimeout:  cfg.Server.ReadTimeout,
-		WriteTimeout: cfg.Server.WriteTimeout,
-		IdleTimeout:  cfg.Server.IdleTimeout,
+		Addr:              cfg.Server.Port,
+		Handler:           router,
+		ReadTimeout:       cfg.Server.ReadTimeout,
+		WriteTimeout:      cfg.Server.WriteTimeout,
+		IdleTimeout:       cfg.Server.IdleTimeout,
+		ReadHeaderTimeout: time.Duration(cfg.Server.ReadHeaderTimeout),
+		MaxHeaderBytes:    cfg.Server.MaxHeaderBytes,
+		ConnState: func(conn net.Conn, state http.ConnState) {
+			switch state {
+			case http.StateNew:
+				atomic.AddInt64(&openConns, 1)
+			case http.StateClosed, http.StateHijacked:
+				atomic.AddInt64(&openConns, -1)
+			}
+		},
+	}
+
+	// Serve HTTPS when a certificate is configured, reloading it from disk
+	// so renewals take effect without a restart.
+	scheme := "http"
+	var certReloader *certs.Reloader
+	if cfg.Server.TLSCertFile != "" {
+		certReloader, err = certs.NewReloader(cfg, logger)
+		if err != nil {
+			logger.Error("Failed to start server: %v", err)
+			os.Exit(1)
+		}
+		server.TLSConfig = &tls.Config{GetCertificate: certReloader.GetCertificate}
+		go certReloader.Watch()
+		scheme = "https"
+	}
+
+	listener, err := net.Listen("tcp", server.Addr)
+	if err != nil {
+		logger.Error("Failed to start server: %v", err)
+		os.Exit(1)
 	}
 
+	bootDuration := time.Since(bootStart)
+	lifecycle.With("event", "listening").With("addr", listener.Addr().String()).With("duration_ms", bootDuration.Milliseconds()).
+		Info("Listening on %s after %v", listener.Addr(), bootDuration)
+
 	// Start server in a goroutine.
 	go func() {
-		logger.Info("🚀 Server starting on http://localhost%s", cfg.Server.Port)
-		logger.Info("📋 Sample tasks loaded and ready for your hackathon!")
-		logger.Info("🌐 Web interface: http://localhost%s", cfg.Server.Port)
-		logger.Info("📖 API docs: http://localhost%s/api/v1/health", cfg.Server.Port)
+		logger.Info("🚀 Server starting on %s://localhost%s%s", scheme, cfg.Server.Port, cfg.Server.BasePath)
+		if cfg.Features.SeedSampleData {
+			logger.Info("📋 Sample tasks loaded and ready for your hackathon!")
+		}
+		logger.Info("🌐 Web interface: %s://localhost%s%s/", scheme, cfg.Server.Port, cfg.Server.BasePath)
+		logger.Info("📖 API docs: %s://localhost%s%s/api/v1/health", scheme, cfg.Server.Port, cfg.Server.BasePath)
 
-		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		var err error
+		if certReloader != nil {
+			err = server.ServeTLS(listener, "", "")
+		} else {
+			err = server.Serve(listener)
+		}
+		if err != nil && err != http.ErrServerClosed {
 			logger.Error("Failed to start server: %v", err)
 			os.Exit(1)
 		}
@@ -86,45 +226,91 @@ func main() {
 	// Wait for interrupt signal to gracefully shutdown the server.
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-	<-quit
+	sig := <-quit
 
-	logger.Info("Shutting down server...")
+	shutdownStart := time.Now()
+	draining := atomic.LoadInt64(&openConns)
+	lifecycle.With("event", "shutdown_initiated").With("signal", sig.String()).With("open_connections", draining).
+		Info("Shutting down server...")
 
 	// Graceful shutdown with timeout.
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(cfg.Server.ShutdownTimeout))
 	defer cancel()
 
-	// Shutdown the server.
+	// Shutdown the server, closing whatever is still open once the timeout
+	// passes.
 	if err := server.Shutdown(ctx); err != nil {
-		logger.Error("Server forced to shutdown: %v", err)
+		if errors.Is(err, context.DeadlineExceeded) {
+			remaining := atomic.LoadInt64(&openConns)
+			lifecycle.With("event", "shutdown_timeout").With("open_connections", remaining).
+				Error("Shutdown timed out after %v; closing %d connections", cfg.Server.ShutdownTimeout, remaining)
+		} else {
+			logger.Error("Server forced to shutdown: %v", err)
+		}
+		server.Close()
 		os.Exit(1)
 	}
 
+	shutdownDuration := time.Since(shutdownStart)
+	lifecycle.With("event", "drained").With("connections", draining).With("duration_ms", shutdownDuration.Milliseconds()).
+		Info("Drained %d connections in %v", draining, shutdownDuration)
+
 	// Cleanup middleware.
 	rateLimitMiddleware.Stop()
+	if certReloader != nil {
+		certReloader.Stop()
+	}
+	if corsOrigins != nil {
+		corsOrigins.Stop()
+	}
 
-	logger.Info("Server gracefully stopped")
+	lifecycle.With("event", "stopped").Info("Server gracefully stopped")
 }
 
 // setupRouter configures and returns the HTTP router.
 func setupRouter(
+	cfg *config.Config,
+	logger *utils.Logger,
 	taskHandler *handlers.TaskHandler,
 	healthHandler *handlers.HealthHandler,
 	staticHandler *handlers.StaticHandler,
-	corsMiddleware *middleware.CORSMiddleware,
+	adminHandler *handlers.AdminHandler,
+	recoveryMiddleware *middleware.RecoveryMiddleware,
+	corsHandler mux.MiddlewareFunc,
 	loggingMiddleware *middleware.LoggingMiddleware,
+	metricsMiddleware *middleware.MetricsMiddleware,
+	compressionMiddleware *middleware.CompressionMiddleware,
 	authMiddleware *middleware.AuthMiddleware,
+	requireAuthMiddleware *middleware.RequireAuthMiddleware,
+	adminRoleMiddleware *middleware.RoleMiddleware,
 	rateLimitMiddleware *middleware.RateLimitMiddleware,
+	concurrencyMiddleware *middleware.ConcurrencyMiddleware,
+	contentTypeMiddleware *middleware.ContentTypeMiddleware,
 ) *mux.Router {
 	router := mux.NewRouter()
 
 	// Apply global middleware.
-	router.Use(corsMiddleware.Handler)
+	router.Use(recoveryMiddleware.Handler)
+	router.Use(corsHandler)
 	router.Use(loggingMiddleware.Handler)
+	router.Use(concurrencyMiddleware.Handler)
+	router.Use(metricsMiddleware.Handler)
+	router.Use(compressionMiddleware.Handler)
 	router.Use(rateLimitMiddleware.Handler)
 
+	// All routes live under the configured base path, if any.
+	base := router
+	if cfg.Server.BasePath != "" {
+		base = router.PathPrefix(cfg.Server.BasePath).Subrouter()
+	}
+
 	// API routes.
-	api := router.PathPrefix("/api/v1").Subrouter()
+	api := base.PathPrefix("/api/v1").Subrouter()
+	if methods, ok := cfg.Server.AllowedMethods["api"]; ok {
+		api.Use(middleware.NewMethodsMiddleware(methods, logger).Handler)
+	}
+	api.Use(contentTypeMiddleware.Handler)
+	api.Use(middleware.NewTimeoutMiddleware(cfg, logger).Handler)
 
 	// Health endpoints (no auth required).
 	api.HandleFunc("/health", healthHandler.HealthCheck).Methods("GET")
@@ -139,19 +325,53 @@ func setupRouter(
 	api.HandleFunc("/tasks", taskHandler.CreateTask).Methods("POST")
 	api.HandleFunc("/tasks/{id:[0-9]+}", taskHandler.GetTask).Methods("GET")
 	api.HandleFunc("/tasks/{id:[0-9]+}", taskHandler.UpdateTask).Methods("PUT")
+	api.HandleFunc("/tasks/{id:[0-9]+}", taskHandler.PatchTask).Methods("PATCH")
 	api.HandleFunc("/tasks/{id:[0-9]+}", taskHandler.DeleteTask).Methods("DELETE")
+	api.HandleFunc("/tasks/{id:[0-9]+}/age", taskHandler.GetTaskAge).Methods("GET")
 
 	// Additional task operations.
 	api.HandleFunc("/tasks/search", taskHandler.SearchTasks).Methods("POST")
+	api.HandleFunc("/tasks/validate", taskHandler.ValidateTask).Methods("POST")
+	api.HandleFunc("/tasks/by-title/{title}", taskHandler.EnsureTaskByTitle).Methods("PUT")
+	api.HandleFunc("/tasks/priority", taskHandler.BulkUpdatePriority).Methods("POST")
 	api.HandleFunc("/tasks/stats", taskHandler.GetTaskStats).Methods("GET")
+	api.HandleFunc("/tasks/stats/metrics", taskHandler.GetTaskStatsMetrics).Methods("GET")
+	api.HandleFunc("/tasks/count", taskHandler.CountTasks).Methods("GET")
+	api.HandleFunc("/tasks/ids", taskHandler.GetTaskIDs).Methods("GET")
+	api.HandleFunc("/tasks/changes", taskHandler.GetTaskChanges).Methods("GET")
+	api.HandleFunc("/tasks/poll", taskHandler.PollTaskChanges).Methods("GET")
+	api.HandleFunc("/tasks/tags", taskHandler.GetTagCounts).Methods("GET")
+	api.HandleFunc("/tasks/summary", taskHandler.GetTaskSummary).Methods("GET")
+
+	// Admin endpoints (authentication and admin role required).
+	admin := api.PathPrefix("/admin").Subrouter()
+	if methods, ok := cfg.Server.AllowedMethods["admin"]; ok {
+		admin.Use(middleware.NewMethodsMiddleware(methods, logger).Handler)
+	}
+	admin.Use(requireAuthMiddleware.Handler)
+	admin.Use(adminRoleMiddleware.Handler)
+	admin.HandleFunc("/ratelimit/reset", adminHandler.ResetRateLimits).Methods("POST")
+	admin.HandleFunc("/ratelimit/clients", adminHandler.GetRateLimitClients).Methods("GET")
+	admin.HandleFunc("/latency", adminHandler.GetLatency).Methods("GET")
+	admin.HandleFunc("/seed", adminHandler.ReseedTasks).Methods("POST")
+
+	// Preflight requests only reach the CORS middleware when a route
+	// matches, so accept OPTIONS on any path.
+	router.Methods("OPTIONS").HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
 
 	// Static content.
-	router.HandleFunc("/", staticHandler.ServeHome).Methods("GET")
+	base.HandleFunc("/", staticHandler.ServeHome).Methods("GET")
+
+	// Handle 405s with an Allow header listing the methods the path accepts.
+	router.MethodNotAllowedHandler = middleware.NewMethodNotAllowedHandler(router, logger)
 
-	// Handle 404s with a custom response.
+	// Handle 404s with the same coded error body as other failures.
+	notFound := utils.NewResponseHelperWithLogger(logger)
 	router.NotFoundHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		response := utils.NewResponseHelper()
-		response.SendError(w, http.StatusNotFound, fmt.Sprintf("Endpoint not found: %s %s", r.Method, r.URL.Path))
+		notFound.WithRequest(r).SendErrorWithCode(w, http.StatusNotFound, "NOT_FOUND", "Endpoint not found",
+			fmt.Sprintf("No route for %s %s", r.Method, r.URL.Path))
 	})
 
 	return router
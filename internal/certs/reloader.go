@@ -0,0 +1,114 @@
+package certs
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"merge-queue/internal/config"
+	"merge-queue/pkg/utils"
+)
+
+// Reloader serves a TLS certificate that is re-read from disk on an
+// interval and on SIGHUP, so renewed certificates take effect without a
+// restart.
+type Reloader struct {
+	certFile string
+	keyFile  string
+	interval time.Duration
+	logger   *utils.Logger
+
+	mutex sync.RWMutex
+	cert  *tls.Certificate
+
+	stop chan struct{}
+	once sync.Once
+}
+
+// NewReloader creates a reloader for the configured certificate and key
+// and loads them once. It fails if the initial load fails.
+func NewReloader(cfg *config.Config, logger *utils.Logger) (*Reloader, error) {
+	cr := &Reloader{
+		certFile: cfg.Server.TLSCertFile,
+		keyFile:  cfg.Server.TLSKeyFile,
+		interval: time.Duration(cfg.Server.TLSReloadInterval),
+		logger:   logger,
+		stop:     make(chan struct{}),
+	}
+
+	if err := cr.Reload(); err != nil {
+		return nil, err
+	}
+
+	return cr, nil
+}
+
+// GetCertificate implements tls.Config.GetCertificate.
+func (cr *Reloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	cr.mutex.RLock()
+	defer cr.mutex.RUnlock()
+
+	return cr.cert, nil
+}
+
+// Reload re-reads the certificate and key. On failure the previously
+// loaded certificate stays in use.
+func (cr *Reloader) Reload() error {
+	cert, err := tls.LoadX509KeyPair(cr.certFile, cr.keyFile)
+	if err != nil {
+		return fmt.Errorf("failed to load TLS certificate: %w", err)
+	}
+
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		return fmt.Errorf("failed to parse TLS certificate: %w", err)
+	}
+	cert.Leaf = leaf
+
+	cr.mutex.Lock()
+	cr.cert = &cert
+	cr.mutex.Unlock()
+
+	cr.logger.With("component", "tls").Info("Loaded TLS certificate for %v, expires %s",
+		leaf.DNSNames, leaf.NotAfter.UTC().Format(time.RFC3339))
+
+	return nil
+}
+
+// Watch reloads the certificate on every interval tick and SIGHUP until
+// Stop is called.
+func (cr *Reloader) Watch() {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	var tick <-chan time.Time
+	if cr.interval > 0 {
+		ticker := time.NewTicker(cr.interval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	for {
+		select {
+		case <-tick:
+		case <-hup:
+		case <-cr.stop:
+			return
+		}
+
+		if err := cr.Reload(); err != nil {
+			cr.logger.Error("Keeping current TLS certificate: %v", err)
+		}
+	}
+}
+
+// Stop ends Watch.
+func (cr *Reloader) Stop() {
+	cr.once.Do(func() { close(cr.stop) })
+}
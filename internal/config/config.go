@@ -1,10 +1,14 @@
 package config
 
 import (
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"os"
+	"reflect"
+	"regexp"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -14,15 +18,26 @@ type Config struct {
 	App      AppConfig      `json:"app"`
 	Features FeaturesConfig `json:"features"`
 	Defaults DefaultsConfig `json:"defaults"`
+	Auth     AuthConfig     `json:"auth"`
+	Storage  StorageConfig  `json:"storage"`
 }
 
 // ServerConfig holds server-related configuration.
 type ServerConfig struct {
-	Port         string        `json:"port"`
-	Host         string        `json:"host"`
-	ReadTimeout  time.Duration `json:"read_timeout"`
-	WriteTimeout time.Duration `json:"write_timeout"`
-	IdleTimeout  time.Duration `json:"idle_timeout"`
+	Port              string              `json:"port"`
+	Host              string              `json:"host"`
+	ReadTimeout       time.Duration       `json:"read_timeout"`
+	WriteTimeout      time.Duration       `json:"write_timeout"`
+	IdleTimeout       time.Duration       `json:"idle_timeout"`
+	HandlerTimeout    Duration            `json:"handler_timeout"`     // Time a handler may run before the client gets a 503; 0 disables. Must be below write_timeout.
+	ReadHeaderTimeout Duration            `json:"read_header_timeout"` // Time allowed to send request headers; bounds slow-header clients.
+	ShutdownTimeout   Duration            `json:"shutdown_timeout"`    // Time open connections get to finish on shutdown before they are closed.
+	MaxHeaderBytes    int                 `json:"max_header_bytes"`
+	BasePath          string              `json:"base_path"`     // URL prefix for every route, e.g. "/taskmgr"; empty serves from the root.
+	TLSCertFile       string              `json:"tls_cert_file"` // Serve HTTPS when both this and tls_key_file are set.
+	TLSKeyFile        string              `json:"tls_key_file"`
+	TLSReloadInterval Duration            `json:"tls_reload_interval"` // How often to re-read the certificate; 0 reloads only on SIGHUP.
+	AllowedMethods    map[string][]string `json:"allowed_methods"`     // Per route group ("api", "admin"); other methods get a 405.
 }
 
 // AppConfig holds application-level configuration.
@@ -31,16 +46,48 @@ type AppConfig struct {
 	Version     string `json:"version"`
 	Debug       bool   `json:"debug"`
 	Environment string `json:"environment"` // "development", "staging", "production"
+	Banner      string `json:"banner"`      // Optional notice shown to API consumers and logged at startup.
+	LogFormat   string `json:"log_format"`  // "text" or "json" (one object per line).
 }
 
 // FeaturesConfig holds feature flags and limits.
 type FeaturesConfig struct {
-	EnableCORS       bool `json:"enable_cors"`
-	EnableLogging    bool `json:"enable_logging"`
-	EnableMetrics    bool `json:"enable_metrics"`
-	MaxTasksPerUser  int  `json:"max_tasks_per_user"`
-	RateLimitPerMin  int  `json:"rate_limit_per_min"`
-	EnableValidation bool `json:"enable_validation"`
+	EnableCORS              bool                `json:"enable_cors"`
+	CORSMaxAge              int                 `json:"cors_max_age"`         // Preflight cache lifetime in seconds; 0 omits the header.
+	CORSAllowedOrigins      []string            `json:"cors_allowed_origins"` // Origins allowed cross-origin, exact or "https://*.example.com"; "*" allows any.
+	CORSOriginsFile         string              `json:"cors_origins_file"`    // Allowed origins, one per line, re-read on SIGHUP; empty uses cors_allowed_origins.
+	HomeMaxAge              int                 `json:"home_max_age"`         // Seconds browsers may cache the home page; 0 makes them revalidate every time.
+	EnableLogging           bool                `json:"enable_logging"`
+	AccessLogFile           string              `json:"access_log_file"`       // Append access logs here as JSON lines; empty logs them with the app logger.
+	LogExcludedPaths        []string            `json:"log_excluded_paths"`    // Path prefixes, below the base path, logged only at debug level.
+	RedactedQueryParams     []string            `json:"redacted_query_params"` // Query params masked in request logs.
+	EnableMetrics           bool                `json:"enable_metrics"`
+	LatencyWindow           Duration            `json:"latency_window"` // Rolling window for latency percentiles; 0 never resets.
+	EnableCompression       bool                `json:"enable_compression"`
+	MinCompressBytes        int                 `json:"min_compress_bytes"` // Responses smaller than this are sent uncompressed.
+	MaxTasksPerUser         int                 `json:"max_tasks_per_user"` // 0 means unlimited.
+	RateLimitPerMin         int                 `json:"rate_limit_per_min"`
+	RateLimitBurst          int                 `json:"rate_limit_burst"`          // Requests a client may send at once; 0 uses rate_limit_per_min.
+	MaxInFlight             int                 `json:"max_in_flight"`             // Concurrent requests allowed before answering 503; 0 means unlimited.
+	RequireJSONContentType  bool                `json:"require_json_content_type"` // Answer 415 to POST/PUT/PATCH bodies not sent as application/json.
+	EnableValidation        bool                `json:"enable_validation"`         // Off skips length, enum, tag and metadata limits; titles stay required.
+	MaxTitleLength          int                 `json:"max_title_length"`
+	MaxDescriptionLength    int                 `json:"max_description_length"`
+	MaxMetadataKeys         int                 `json:"max_metadata_keys"`
+	MaxMetadataKeyLength    int                 `json:"max_metadata_key_length"`
+	MaxMetadataValueLength  int                 `json:"max_metadata_value_length"`
+	TagPattern              string              `json:"tag_pattern"`               // Regexp each whole tag must match, e.g. "[a-z0-9-]+"; empty allows any characters.
+	SearchableFields        []string            `json:"searchable_fields"`         // Values accepted in a search's fields and field_weights.
+	SortableFields          []string            `json:"sortable_fields"`           // Values accepted in a search's sort_by.
+	MaxSearchResults        int                 `json:"max_search_results"`        // Cap on tasks a search returns, after sorting; 0 means unlimited.
+	CaseInsensitiveMatching bool                `json:"case_insensitive_matching"` // Lowercase statuses/priorities on input; compare filters ignoring case.
+	RequireAssignee         bool                `json:"require_assignee"`          // Reject creates, and updates that clear assigned_to, without an assignee.
+	HideClosedTasks         bool                `json:"hide_closed_tasks"`         // Leave completed/cancelled tasks out of listings unless include_completed=true.
+	StatusTransitions       map[string][]string `json:"status_transitions"`        // Statuses each status may move to; empty uses the built-in workflow.
+	CapacityWarnPercent     int                 `json:"capacity_warn_percent"`     // Store usage % above which health reports "degraded".
+	UptimeCacheTTL          Duration            `json:"uptime_cache_ttl"`          // How long health checks reuse the formatted uptime; 0 disables caching.
+	SeedSampleData          bool                `json:"seed_sample_data"`
+	SeedFile                string              `json:"seed_file"` // JSON array of tasks to seed; built-in samples are used if absent.
 }
 
 // DefaultsConfig holds default values for various entities.
@@ -51,6 +98,18 @@ type DefaultsConfig struct {
 	PageSize     int    `json:"page_size"`
 }
 
+// StorageConfig holds task persistence configuration.
+type StorageConfig struct {
+	Path          string `json:"path"`           // JSON file tasks are loaded from and saved to on every change; empty keeps tasks in memory only.
+	EncryptionKey string `json:"encryption_key"` // Base64 AES key (16, 24 or 32 bytes) encrypting descriptions and metadata values in the file; empty stores plaintext.
+}
+
+// AuthConfig holds authentication-related configuration.
+type AuthConfig struct {
+	AdminToken string   `json:"admin_token"` // Bearer token granted the admin role; empty disables admin access.
+	Roles      []string `json:"roles"`       // User roles from least to most privileged; must include "user" and "admin".
+}
+
 // LoadConfig loads configuration from a JSON file with environment variable overrides.
 func LoadConfig(filename string) (*Config, error) {
 	config := &Config{}
@@ -79,11 +138,19 @@ func LoadConfig(filename string) (*Config, error) {
 // setDefaults sets default configuration values.
 func (c *Config) setDefaults() {
 	c.Server = ServerConfig{
-		Port:         ":8080",
-		Host:         "localhost",
-		ReadTimeout:  15 * time.Second,
-		WriteTimeout: 15 * time.Second,
-		IdleTimeout:  60 * time.Second,
+		Port:              ":8080",
+		Host:              "localhost",
+		ReadTimeout:       15 * time.Second,
+		WriteTimeout:      15 * time.Second,
+		IdleTimeout:       60 * time.Second,
+		HandlerTimeout:    Duration(10 * time.Second),
+		ReadHeaderTimeout: Duration(5 * time.Second),
+		ShutdownTimeout:   Duration(30 * time.Second),
+		MaxHeaderBytes:    64 << 10,
+		AllowedMethods: map[string][]string{
+			"api":   {"GET", "POST", "PUT", "PATCH", "DELETE"},
+			"admin": {"GET", "POST"},
+		},
 	}
 
 	c.App = AppConfig{
@@ -91,15 +158,39 @@ func (c *Config) setDefaults() {
 		Version:     "1.0.0",
 		Debug:       false,
 		Environment: "development",
+		LogFormat:   "text",
 	}
 
 	c.Features = FeaturesConfig{
-		EnableCORS:       true,
-		EnableLogging:    true,
-		EnableMetrics:    false,
-		MaxTasksPerUser:  100,
-		RateLimitPerMin:  60,
-		EnableValidation: true,
+		EnableCORS:             true,
+		CORSMaxAge:             86400,
+		CORSAllowedOrigins:     []string{"*"},
+		HomeMaxAge:             300,
+		EnableLogging:          true,
+		RedactedQueryParams:    []string{"token", "api_key"},
+		LogExcludedPaths:       []string{"/api/v1/health", "/api/v1/ready", "/api/v1/live"},
+		EnableMetrics:          false,
+		LatencyWindow:          Duration(5 * time.Minute),
+		EnableCompression:      true,
+		MinCompressBytes:       1024,
+		MaxTasksPerUser:        100,
+		RateLimitPerMin:        60,
+		EnableValidation:       true,
+		MaxTitleLength:         200,
+		MaxDescriptionLength:   1000,
+		MaxMetadataKeys:        20,
+		MaxMetadataKeyLength:   50,
+		MaxMetadataValueLength: 500,
+		SearchableFields:       []string{"title", "description"},
+		SortableFields:         []string{"relevance", "created_at", "updated_at", "priority"},
+		MaxSearchResults:       1000,
+		CapacityWarnPercent:    80,
+		SeedSampleData:         true,
+		UptimeCacheTTL:         Duration(time.Second),
+	}
+
+	c.Auth = AuthConfig{
+		Roles: []string{"viewer", "user", "admin"},
 	}
 
 	c.Defaults = DefaultsConfig{
@@ -112,18 +203,37 @@ func (c *Config) setDefaults() {
 
 // loadFromFile loads configuration from a JSON file.
 func (c *Config) loadFromFile(filename string) error {
-	file, err := os.Open(filename)
+	info, err := os.Stat(filename)
 	if err != nil {
 		// File doesn't exist is not an error - we'll use defaults.
 		if os.IsNotExist(err) {
 			return nil
 		}
+		if os.IsPermission(err) {
+			return fmt.Errorf("cannot access config file %s: permission denied", filename)
+		}
+		return err
+	}
+
+	if info.IsDir() {
+		return fmt.Errorf("config path %s is a directory, expected a JSON file", filename)
+	}
+
+	file, err := os.Open(filename)
+	if err != nil {
+		if os.IsPermission(err) {
+			return fmt.Errorf("cannot read config file %s: permission denied", filename)
+		}
 		return err
 	}
 	defer file.Close()
 
 	decoder := json.NewDecoder(file)
-	return decoder.Decode(c)
+	if err := decoder.Decode(c); err != nil {
+		return fmt.Errorf("invalid JSON in config file %s: %w", filename, err)
+	}
+
+	return nil
 }
 
 // loadFromEnv loads configuration from environment variables.
@@ -135,6 +245,40 @@ func (c *Config) loadFromEnv() {
 		c.Server.Port = port
 	}
 
+	if certFile := os.Getenv("TLS_CERT_FILE"); certFile != "" {
+		c.Server.TLSCertFile = certFile
+	}
+
+	if keyFile := os.Getenv("TLS_KEY_FILE"); keyFile != "" {
+		c.Server.TLSKeyFile = keyFile
+	}
+
+	if accessLog := os.Getenv("ACCESS_LOG_FILE"); accessLog != "" {
+		c.Features.AccessLogFile = accessLog
+	}
+
+	if originsFile := os.Getenv("CORS_ORIGINS_FILE"); originsFile != "" {
+		c.Features.CORSOriginsFile = originsFile
+	}
+
+	if storagePath := os.Getenv("STORAGE_PATH"); storagePath != "" {
+		c.Storage.Path = storagePath
+	}
+
+	if encryptionKey := os.Getenv("STORAGE_ENCRYPTION_KEY"); encryptionKey != "" {
+		c.Storage.EncryptionKey = encryptionKey
+	}
+
+	if shutdownTimeout := os.Getenv("SHUTDOWN_TIMEOUT"); shutdownTimeout != "" {
+		if val, err := time.ParseDuration(shutdownTimeout); err == nil {
+			c.Server.ShutdownTimeout = Duration(val)
+		}
+	}
+
+	if basePath := os.Getenv("BASE_PATH"); basePath != "" {
+		c.Server.BasePath = basePath
+	}
+
 	if host := os.Getenv("HOST"); host != "" {
 		c.Server.Host = host
 	}
@@ -143,10 +287,18 @@ func (c *Config) loadFromEnv() {
 		c.App.Debug = debug == "true" || debug == "1"
 	}
 
+	if adminToken := os.Getenv("ADMIN_TOKEN"); adminToken != "" {
+		c.Auth.AdminToken = adminToken
+	}
+
 	if env := os.Getenv("ENVIRONMENT"); env != "" {
 		c.App.Environment = env
 	}
 
+	if logFormat := os.Getenv("LOG_FORMAT"); logFormat != "" {
+		c.App.LogFormat = logFormat
+	}
+
 	if maxTasks := os.Getenv("MAX_TASKS_PER_USER"); maxTasks != "" {
 		if val, err := strconv.Atoi(maxTasks); err == nil {
 			c.Features.MaxTasksPerUser = val
@@ -158,6 +310,18 @@ func (c *Config) loadFromEnv() {
 			c.Features.RateLimitPerMin = val
 		}
 	}
+
+	if burst := os.Getenv("RATE_LIMIT_BURST"); burst != "" {
+		if val, err := strconv.Atoi(burst); err == nil {
+			c.Features.RateLimitBurst = val
+		}
+	}
+
+	if maxInFlight := os.Getenv("MAX_IN_FLIGHT"); maxInFlight != "" {
+		if val, err := strconv.Atoi(maxInFlight); err == nil {
+			c.Features.MaxInFlight = val
+		}
+	}
 }
 
 // Validate checks if the configuration is valid.
@@ -166,6 +330,54 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("server port is required")
 	}
 
+	c.Server.BasePath = normalizeBasePath(c.Server.BasePath)
+
+	if c.Server.ReadTimeout < 0 || c.Server.WriteTimeout < 0 || c.Server.IdleTimeout < 0 {
+		return fmt.Errorf("server timeouts must not be negative")
+	}
+
+	if c.Server.ReadHeaderTimeout <= 0 {
+		return fmt.Errorf("read_header_timeout must be positive")
+	}
+
+	if c.Server.ReadTimeout > 0 && time.Duration(c.Server.ReadHeaderTimeout) > c.Server.ReadTimeout {
+		return fmt.Errorf("read_header_timeout must not exceed read_timeout")
+	}
+
+	// The handler timeout must fire before the write timeout cuts the
+	// connection, or clients never see the 503.
+	if c.Server.HandlerTimeout < 0 {
+		return fmt.Errorf("handler_timeout must not be negative")
+	}
+	if c.Server.HandlerTimeout > 0 && c.Server.WriteTimeout > 0 && time.Duration(c.Server.HandlerTimeout) >= c.Server.WriteTimeout {
+		return fmt.Errorf("handler_timeout must be shorter than write_timeout")
+	}
+
+	if c.Server.ShutdownTimeout <= 0 {
+		return fmt.Errorf("shutdown_timeout must be positive")
+	}
+
+	if c.Server.MaxHeaderBytes <= 0 {
+		return fmt.Errorf("max_header_bytes must be positive")
+	}
+
+	if (c.Server.TLSCertFile == "") != (c.Server.TLSKeyFile == "") {
+		return fmt.Errorf("tls_cert_file and tls_key_file must be set together")
+	}
+
+	if c.Storage.EncryptionKey != "" {
+		if c.Storage.Path == "" {
+			return fmt.Errorf("storage.encryption_key requires storage.path")
+		}
+		if _, err := c.Storage.Key(); err != nil {
+			return err
+		}
+	}
+
+	if c.Server.TLSReloadInterval < 0 {
+		return fmt.Errorf("tls_reload_interval must not be negative")
+	}
+
 	if c.App.Name == "" {
 		return fmt.Errorf("app name is required")
 	}
@@ -174,6 +386,8 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("app version is required")
 	}
 
+	c.App.Environment = normalizeEnvironment(c.App.Environment)
+
 	validEnvs := []string{"development", "staging", "production"}
 	validEnv := false
 	for _, env := range validEnvs {
@@ -186,21 +400,248 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("invalid environment: %s", c.App.Environment)
 	}
 
-	if c.Features.MaxTasksPerUser <= 0 {
-		return fmt.Errorf("max_tasks_per_user must be positive")
+	c.App.LogFormat = strings.ToLower(strings.TrimSpace(c.App.LogFormat))
+	if c.App.LogFormat != "text" && c.App.LogFormat != "json" {
+		return fmt.Errorf("invalid log_format: %s (must be \"text\" or \"json\")", c.App.LogFormat)
+	}
+
+	if c.Features.CORSMaxAge < 0 {
+		return fmt.Errorf("cors_max_age must not be negative")
+	}
+
+	for _, origin := range c.Features.CORSAllowedOrigins {
+		if strings.TrimSpace(origin) == "" {
+			return fmt.Errorf("cors_allowed_origins must not contain empty entries")
+		}
+	}
+
+	if c.Features.HomeMaxAge < 0 {
+		return fmt.Errorf("home_max_age must not be negative")
+	}
+
+	if c.Features.MinCompressBytes < 0 {
+		return fmt.Errorf("min_compress_bytes must not be negative")
+	}
+
+	if c.Features.MaxTitleLength <= 0 {
+		return fmt.Errorf("max_title_length must be positive")
+	}
+
+	if c.Features.MaxDescriptionLength <= 0 {
+		return fmt.Errorf("max_description_length must be positive")
+	}
+
+	if c.Features.MaxMetadataKeys <= 0 {
+		return fmt.Errorf("max_metadata_keys must be positive")
+	}
+
+	if c.Features.MaxMetadataKeyLength <= 0 {
+		return fmt.Errorf("max_metadata_key_length must be positive")
+	}
+
+	if c.Features.MaxMetadataValueLength <= 0 {
+		return fmt.Errorf("max_metadata_value_length must be positive")
+	}
+
+	if c.Features.TagPattern != "" {
+		if _, err := regexp.Compile(c.Features.TagPattern); err != nil {
+			return fmt.Errorf("invalid tag_pattern: %w", err)
+		}
+	}
+
+	if c.Features.MaxSearchResults < 0 {
+		return fmt.Errorf("max_search_results must not be negative")
+	}
+
+	if c.Features.MaxInFlight < 0 {
+		return fmt.Errorf("max_in_flight must not be negative")
+	}
+
+	// A max_tasks_per_user of 0 means unlimited.
+	if c.Features.MaxTasksPerUser < 0 {
+		return fmt.Errorf("max_tasks_per_user must be zero (unlimited) or positive")
 	}
 
 	if c.Features.RateLimitPerMin <= 0 {
 		return fmt.Errorf("rate_limit_per_min must be positive")
 	}
 
+	if c.Features.RateLimitBurst < 0 {
+		return fmt.Errorf("rate_limit_burst must not be negative")
+	}
+
+	// Only fields the search implements may be allowed; anything else would
+	// be accepted from clients but never match.
+	if err := validateSubset("searchable_fields", c.Features.SearchableFields, []string{"title", "description"}); err != nil {
+		return err
+	}
+	if err := validateSubset("sortable_fields", c.Features.SortableFields, []string{"relevance", "created_at", "updated_at", "priority"}); err != nil {
+		return err
+	}
+
+	statuses := []string{"pending", "in-progress", "completed", "cancelled"}
+	for from, to := range c.Features.StatusTransitions {
+		if err := validateSubset("status_transitions", append([]string{from}, to...), statuses); err != nil {
+			return err
+		}
+	}
+
+	if c.Features.CapacityWarnPercent <= 0 || c.Features.CapacityWarnPercent > 100 {
+		return fmt.Errorf("capacity_warn_percent must be between 1 and 100")
+	}
+
 	if c.Defaults.PageSize <= 0 {
 		return fmt.Errorf("default page_size must be positive")
 	}
 
+	if err := validateRoles(c.Auth.Roles, c.Defaults.UserRole); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// validateSubset checks that every entry of a list setting is one of known.
+func validateSubset(name string, values, known []string) error {
+	for _, value := range values {
+		valid := false
+		for _, k := range known {
+			if value == k {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return fmt.Errorf("%s: unsupported value %q (supported: %s)", name, value, strings.Join(known, ", "))
+		}
+	}
 	return nil
 }
 
+// validateRoles checks the role hierarchy: it must be non-empty, free of
+// blank and duplicate names, and include the roles tokens are granted and
+// the default user role.
+func validateRoles(roles []string, defaultRole string) error {
+	if len(roles) == 0 {
+		return fmt.Errorf("auth roles must not be empty")
+	}
+
+	seen := make(map[string]bool, len(roles))
+	for _, role := range roles {
+		if strings.TrimSpace(role) == "" {
+			return fmt.Errorf("auth roles must not contain blank names")
+		}
+		if seen[role] {
+			return fmt.Errorf("auth role %q is listed more than once", role)
+		}
+		seen[role] = true
+	}
+
+	for _, required := range []string{"user", "admin", defaultRole} {
+		if !seen[required] {
+			return fmt.Errorf("auth roles must include %q", required)
+		}
+	}
+
+	return nil
+}
+
+// normalizeBasePath gives a base path exactly one leading slash and no
+// trailing slash, so "taskmgr/" becomes "/taskmgr" and "/" becomes "".
+func normalizeBasePath(path string) string {
+	path = strings.Trim(strings.TrimSpace(path), "/")
+	if path == "" {
+		return ""
+	}
+	return "/" + path
+}
+
+// normalizeEnvironment trims and lowercases an environment name and maps
+// common aliases to their canonical form.
+func normalizeEnvironment(env string) string {
+	env = strings.ToLower(strings.TrimSpace(env))
+
+	aliases := map[string]string{
+		"dev":   "development",
+		"stage": "staging",
+		"prod":  "production",
+	}
+	if canonical, ok := aliases[env]; ok {
+		return canonical
+	}
+
+	return env
+}
+
+// sensitiveKeyParts marks config keys whose values are masked in Summary.
+var sensitiveKeyParts = []string{"secret", "password", "token", "api_key", "private_key", "encryption_key"}
+
+// Summary returns a human-readable dump of the effective configuration,
+// one "section.key = value" per line, with sensitive values masked.
+func (c *Config) Summary() string {
+	var lines []string
+	summarizeStruct(reflect.ValueOf(*c), "", &lines)
+	return strings.Join(lines, "\n")
+}
+
+// summarizeStruct appends a line per leaf field of v, keyed by JSON name.
+func summarizeStruct(v reflect.Value, prefix string, lines *[]string) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if name == "" || name == "-" {
+			name = strings.ToLower(field.Name)
+		}
+		key := prefix + name
+
+		value := v.Field(i)
+		if value.Kind() == reflect.Struct && value.Type() != reflect.TypeOf(time.Time{}) {
+			summarizeStruct(value, key+".", lines)
+			continue
+		}
+
+		display := fmt.Sprintf("%v", value.Interface())
+		if isSensitiveKey(name) && display != "" {
+			display = "********"
+		}
+		*lines = append(*lines, fmt.Sprintf("%s = %s", key, display))
+	}
+}
+
+// isSensitiveKey reports whether a config key holds a secret.
+func isSensitiveKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, part := range sensitiveKeyParts {
+		if strings.Contains(key, part) {
+			return true
+		}
+	}
+	return false
+}
+
+// Key decodes EncryptionKey. It returns nil when no key is set.
+func (sc StorageConfig) Key() ([]byte, error) {
+	if sc.EncryptionKey == "" {
+		return nil, nil
+	}
+
+	key, err := base64.StdEncoding.DecodeString(sc.EncryptionKey)
+	if err != nil {
+		return nil, fmt.Errorf("storage.encryption_key must be base64: %w", err)
+	}
+	switch len(key) {
+	case 16, 24, 32:
+		return key, nil
+	default:
+		return nil, fmt.Errorf("storage.encryption_key must decode to 16, 24 or 32 bytes, got %d", len(key))
+	}
+}
+
 // IsDevelopment returns true if running in development mode.
 func (c *Config) IsDevelopment() bool {
 	return c.App.Environment == "development"
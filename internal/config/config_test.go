@@ -0,0 +1,181 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newTestConfig returns a config holding only the defaults.
+func newTestConfig(t *testing.T) *Config {
+	t.Helper()
+
+	cfg := &Config{}
+	cfg.setDefaults()
+	return cfg
+}
+
+func TestValidateMaxTasksPerUser(t *testing.T) {
+	tests := []struct {
+		name     string
+		maxTasks int
+		wantErr  bool
+	}{
+		{name: "zero is unlimited", maxTasks: 0},
+		{name: "positive cap", maxTasks: 5},
+		{name: "negative rejected", maxTasks: -1, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := newTestConfig(t)
+			cfg.Features.MaxTasksPerUser = tt.maxTasks
+
+			err := cfg.Validate()
+			if tt.wantErr {
+				if err == nil || !strings.Contains(err.Error(), "max_tasks_per_user") {
+					t.Fatalf("Validate() error = %v, want max_tasks_per_user error", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Validate() error = %v, want nil", err)
+			}
+		})
+	}
+}
+
+func TestLoadConfigMissingFileUsesDefaults(t *testing.T) {
+	cfg, err := LoadConfig(filepath.Join(t.TempDir(), "missing.json"))
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v, want nil", err)
+	}
+	if cfg.App.Name != "Task Manager API" {
+		t.Fatalf("App.Name = %q, want the default", cfg.App.Name)
+	}
+}
+
+func TestLoadConfigDirectory(t *testing.T) {
+	dir := t.TempDir()
+
+	_, err := LoadConfig(dir)
+	if err == nil || !strings.Contains(err.Error(), "is a directory") {
+		t.Fatalf("LoadConfig() error = %v, want directory error", err)
+	}
+}
+
+func TestLoadConfigPermissionDenied(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("file permissions are not enforced for root")
+	}
+
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte("{}"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(path, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := LoadConfig(path)
+	if err == nil || !strings.Contains(err.Error(), "permission denied") {
+		t.Fatalf("LoadConfig() error = %v, want permission error", err)
+	}
+}
+
+func TestLoadConfigDurations(t *testing.T) {
+	if os.Getenv("SHUTDOWN_TIMEOUT") != "" {
+		t.Skip("SHUTDOWN_TIMEOUT overrides the file")
+	}
+
+	path := filepath.Join(t.TempDir(), "config.json")
+	data := `{
+		"server": {"shutdown_timeout": "45s", "handler_timeout": "2s", "read_header_timeout": 3000000000, "tls_reload_interval": "1h"},
+		"features": {"latency_window": "10m", "uptime_cache_ttl": "0s"}
+	}`
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+
+	tests := []struct {
+		name string
+		got  Duration
+		want time.Duration
+	}{
+		{name: "shutdown_timeout", got: cfg.Server.ShutdownTimeout, want: 45 * time.Second},
+		{name: "handler_timeout", got: cfg.Server.HandlerTimeout, want: 2 * time.Second},
+		{name: "read_header_timeout", got: cfg.Server.ReadHeaderTimeout, want: 3 * time.Second},
+		{name: "tls_reload_interval", got: cfg.Server.TLSReloadInterval, want: time.Hour},
+		{name: "latency_window", got: cfg.Features.LatencyWindow, want: 10 * time.Minute},
+		{name: "uptime_cache_ttl", got: cfg.Features.UptimeCacheTTL, want: 0},
+	}
+	for _, tt := range tests {
+		if time.Duration(tt.got) != tt.want {
+			t.Errorf("%s = %v, want %v", tt.name, tt.got, tt.want)
+		}
+	}
+}
+
+func TestDurationUnmarshalInvalid(t *testing.T) {
+	for _, data := range []string{`"soon"`, `true`, `"45"`} {
+		var d Duration
+		if err := json.Unmarshal([]byte(data), &d); err == nil {
+			t.Errorf("Unmarshal(%s) error = nil, want an error", data)
+		}
+	}
+}
+
+func TestValidateStorageEncryptionKey(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    string
+		key     string
+		wantErr string
+	}{
+		{name: "no key", path: "tasks.json"},
+		{name: "32 byte key", path: "tasks.json", key: "MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY="},
+		{name: "not base64", path: "tasks.json", key: "not base64!", wantErr: "must be base64"},
+		{name: "wrong length", path: "tasks.json", key: "c2hvcnQ=", wantErr: "16, 24 or 32 bytes, got 5"},
+		{name: "no path", key: "MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY=", wantErr: "requires storage.path"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := newTestConfig(t)
+			cfg.Storage.Path = tt.path
+			cfg.Storage.EncryptionKey = tt.key
+
+			err := cfg.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Validate() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Validate() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSummaryMasksEncryptionKey(t *testing.T) {
+	cfg := newTestConfig(t)
+	cfg.Storage.EncryptionKey = "MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY="
+
+	summary := cfg.Summary()
+	if strings.Contains(summary, cfg.Storage.EncryptionKey) {
+		t.Fatal("Summary() contains the encryption key")
+	}
+	if !strings.Contains(summary, "storage.encryption_key = ********") {
+		t.Fatalf("Summary() does not mask storage.encryption_key:\n%s", summary)
+	}
+}
@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"merge-queue/internal/middleware"
+	"merge-queue/internal/services"
+	"merge-queue/pkg/utils"
+)
+
+// AdminHandler handles operational endpoints reserved for administrators.
+type AdminHandler struct {
+	taskService *services.TaskService
+	rateLimiter *middleware.RateLimitMiddleware
+	metrics     *middleware.MetricsMiddleware
+	concurrency *middleware.ConcurrencyMiddleware
+	response    *utils.ResponseHelper
+	logger      *utils.Logger
+}
+
+// NewAdminHandler creates a new AdminHandler instance.
+func NewAdminHandler(taskService *services.TaskService, rateLimiter *middleware.RateLimitMiddleware, metrics *middleware.MetricsMiddleware, concurrency *middleware.ConcurrencyMiddleware, logger *utils.Logger) *AdminHandler {
+	return &AdminHandler{
+		taskService: taskService,
+		rateLimiter: rateLimiter,
+		metrics:     metrics,
+		concurrency: concurrency,
+		response:    utils.NewResponseHelperWithLogger(logger),
+		logger:      logger,
+	}
+}
+
+// ResetRateLimits handles POST /admin/ratelimit/reset requests.
+func (ah *AdminHandler) ResetRateLimits(w http.ResponseWriter, r *http.Request) {
+	cleared := ah.rateLimiter.Reset()
+
+	utils.LoggerFromContext(r.Context(), ah.logger).Info("Rate limit state reset, %d clients cleared", cleared)
+
+	response := map[string]interface{}{
+		"clients_cleared": cleared,
+	}
+
+	ah.response.WithRequest(r).SendSuccess(w, response)
+}
+
+// maxRateLimitClients caps how many clients GetRateLimitClients returns.
+const maxRateLimitClients = 100
+
+// GetRateLimitClients handles GET /admin/ratelimit/clients requests. Only
+// the most recently seen clients are returned; X-Total-Count carries how
+// many are tracked.
+func (ah *AdminHandler) GetRateLimitClients(w http.ResponseWriter, r *http.Request) {
+	clients, total := ah.rateLimiter.Clients(maxRateLimitClients)
+
+	response := map[string]interface{}{
+		"clients":   clients,
+		"count":     len(clients),
+		"total":     total,
+		"truncated": len(clients) < total,
+	}
+
+	ah.response.SetTotalCount(w, total)
+	ah.response.WithRequest(r).SendSuccess(w, response)
+}
+
+// GetLatency handles GET /admin/latency requests.
+func (ah *AdminHandler) GetLatency(w http.ResponseWriter, r *http.Request) {
+	snapshot := ah.metrics.Latency()
+	snapshot.InFlight = ah.concurrency.InFlight()
+
+	ah.response.WithRequest(r).SendSuccess(w, snapshot)
+}
+
+// ReseedTasks handles POST /admin/seed requests.
+func (ah *AdminHandler) ReseedTasks(w http.ResponseWriter, r *http.Request) {
+	logger := utils.LoggerFromContext(r.Context(), ah.logger)
+
+	ids, err := ah.taskService.Reseed()
+	if errors.Is(err, services.ErrReseedDisabled) {
+		ah.response.WithRequest(r).SendError(w, http.StatusForbidden, err.Error())
+		return
+	}
+	if err != nil {
+		logger.Error("Failed to reseed tasks: %v", err)
+		ah.response.WithRequest(r).SendInternalError(w, "Failed to save tasks", err)
+		return
+	}
+
+	logger.Info("Sample data reseeded, %d tasks created", len(ids))
+
+	response := map[string]interface{}{
+		"ids":   ids,
+		"count": len(ids),
+	}
+	if seedErrors := ah.taskService.SeedErrors(); len(seedErrors) > 0 {
+		messages := make([]string, 0, len(seedErrors))
+		for _, seedErr := range seedErrors {
+			logger.Warn("Sample data: %v", seedErr)
+			messages = append(messages, seedErr.Error())
+		}
+		response["errors"] = messages
+	}
+
+	ah.response.WithRequest(r).SendSuccess(w, response)
+}
@@ -1,58 +1,85 @@
 package handlers
 
 import (
+	"context"
 	"net/http"
+	"sync"
 	"time"
 
 	"merge-queue/internal/config"
 	"merge-queue/internal/models"
+	"merge-queue/internal/services"
 	"merge-queue/pkg/utils"
 )
 
 // HealthHandler handles health check requests.
 type HealthHandler struct {
-	config    *config.Config
-	response  *utils.ResponseHelper
-	logger    *utils.Logger
-	startTime time.Time
+	config      *config.Config
+	taskService *services.TaskService
+	response    *utils.ResponseHelper
+	logger      *utils.Logger
+	timeUtils   *utils.TimeUtils
+	startTime   time.Time
+
+	uptimeMutex    sync.Mutex
+	cachedUptime   string
+	uptimeCachedAt time.Time
 }
 
 // NewHealthHandler creates a new HealthHandler instance.
-func NewHealthHandler(cfg *config.Config, logger *utils.Logger) *HealthHandler {
+func NewHealthHandler(cfg *config.Config, taskService *services.TaskService, logger *utils.Logger) *HealthHandler {
 	return &HealthHandler{
-		config:    cfg,
-		response:  utils.NewResponseHelper(),
-		logger:    logger,
-		startTime: time.Now(),
+		config:      cfg,
+		taskService: taskService,
+		response:    utils.NewResponseHelperWithLogger(logger),
+		logger:      logger,
+		timeUtils:   utils.NewTimeUtils(),
+		startTime:   time.Now(),
 	}
 }
 
 // HealthCheck handles GET /health requests.
 func (hh *HealthHandler) HealthCheck(w http.ResponseWriter, r *http.Request) {
-	uptime := time.Since(hh.startTime)
+	used, max := hh.taskService.Capacity()
+
+	status := "healthy"
+	if hh.isNearCapacity(used, max) {
+		status = "degraded"
+	}
 
 	response := models.HealthResponse{
-		Status:    "healthy",
+		Status:    status,
 		Version:   hh.config.App.Version,
-		Timestamp: time.Now(),
-		Uptime:    utils.NewTimeUtils().FormatDuration(uptime),
+		Timestamp: time.Now().UTC(),
+		Uptime:    hh.uptime(),
+		TasksUsed: used,
+		TasksMax:  max,
+		Banner:    hh.config.App.Banner,
 	}
 
-	hh.response.SendSuccess(w, response)
+	hh.response.WithRequest(r).SendSuccess(w, response)
 }
 
+// storePingTimeout bounds the task store write check in readiness probes.
+const storePingTimeout = 2 * time.Second
+
 // ReadinessCheck handles GET /ready requests.
 func (hh *HealthHandler) ReadinessCheck(w http.ResponseWriter, r *http.Request) {
-	// In a real application, you'd check database connectivity,
-	// external service availability, etc.
-
 	checks := map[string]string{
-		"database":     "ok", // Placeholder.
+		"storage":      "ok",
 		"external_api": "ok", // Placeholder.
 		"memory":       "ok", // Could check memory usage.
 		"disk":         "ok", // Could check disk space.
 	}
 
+	// A task store that no longer accepts writes makes the service unready.
+	ctx, cancel := context.WithTimeout(r.Context(), storePingTimeout)
+	defer cancel()
+	if err := hh.taskService.PingStore(ctx); err != nil {
+		utils.LoggerFromContext(r.Context(), hh.logger).Error("Task store readiness check failed: %v", err)
+		checks["storage"] = "error"
+	}
+
 	allHealthy := true
 	for _, status := range checks {
 		if status != "ok" {
@@ -61,15 +88,29 @@ func (hh *HealthHandler) ReadinessCheck(w http.ResponseWriter, r *http.Request)
 		}
 	}
 
+	// A task store near capacity is degraded but still ready.
+	used, max := hh.taskService.Capacity()
+	degraded := hh.isNearCapacity(used, max)
+	if degraded {
+		checks["task_store"] = "degraded"
+	} else {
+		checks["task_store"] = "ok"
+	}
+
 	response := map[string]interface{}{
 		"status": func() string {
-			if allHealthy {
-				return "ready"
+			if !allHealthy {
+				return "not_ready"
+			}
+			if degraded {
+				return "degraded"
 			}
-			return "not_ready"
+			return "ready"
 		}(),
-		"checks":    checks,
-		"timestamp": time.Now(),
+		"checks":     checks,
+		"tasks_used": used,
+		"tasks_max":  max,
+		"timestamp":  time.Now().UTC(),
 	}
 
 	statusCode := http.StatusOK
@@ -77,8 +118,7 @@ func (hh *HealthHandler) ReadinessCheck(w http.ResponseWriter, r *http.Request)
 		statusCode = http.StatusServiceUnavailable
 	}
 
-	w.WriteHeader(statusCode)
-	hh.response.SendJSON(w, statusCode, response)
+	hh.response.WithRequest(r).SendJSON(w, statusCode, response)
 }
 
 // LivenessCheck handles GET /live requests.
@@ -86,9 +126,38 @@ func (hh *HealthHandler) LivenessCheck(w http.ResponseWriter, r *http.Request) {
 	// Simple liveness check - if we can respond, we're alive.
 	response := map[string]interface{}{
 		"status":    "alive",
-		"timestamp": time.Now(),
-		"uptime":    utils.NewTimeUtils().FormatDuration(time.Since(hh.startTime)),
+		"timestamp": time.Now().UTC(),
+		"uptime":    hh.uptime(),
 	}
 
-	hh.response.SendSuccess(w, response)
+	hh.response.WithRequest(r).SendSuccess(w, response)
+}
+
+// uptime returns the formatted uptime, reusing a cached value for up to
+// the configured UptimeCacheTTL.
+func (hh *HealthHandler) uptime() string {
+	ttl := time.Duration(hh.config.Features.UptimeCacheTTL)
+	if ttl <= 0 {
+		return hh.timeUtils.FormatDuration(time.Since(hh.startTime))
+	}
+
+	hh.uptimeMutex.Lock()
+	defer hh.uptimeMutex.Unlock()
+
+	now := time.Now()
+	if hh.cachedUptime == "" || now.Sub(hh.uptimeCachedAt) >= ttl {
+		hh.cachedUptime = hh.timeUtils.FormatDuration(now.Sub(hh.startTime))
+		hh.uptimeCachedAt = now
+	}
+
+	return hh.cachedUptime
+}
+
+// isNearCapacity reports whether task-store usage exceeds the configured
+// warning percentage.
+func (hh *HealthHandler) isNearCapacity(used, max int) bool {
+	if max <= 0 {
+		return false
+	}
+	return used*100 > max*hh.config.Features.CapacityWarnPercent
 }
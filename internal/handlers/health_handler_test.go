@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"merge-queue/internal/config"
+	"merge-queue/internal/services"
+	"merge-queue/pkg/utils"
+)
+
+// newTestConfig returns the default config without sample data.
+func newTestConfig(tb testing.TB) *config.Config {
+	tb.Helper()
+
+	cfg, err := config.LoadConfig("")
+	if err != nil {
+		tb.Fatalf("LoadConfig() error = %v", err)
+	}
+	cfg.Features.SeedSampleData = false
+	return cfg
+}
+
+// newTestLogger returns a logger that only reports errors.
+func newTestLogger() *utils.Logger {
+	return utils.NewLogger(utils.ErrorLevel)
+}
+
+func BenchmarkHealthCheck(b *testing.B) {
+	benchmarks := []struct {
+		name string
+		ttl  time.Duration
+	}{
+		{name: "cached", ttl: time.Minute},
+		{name: "uncached", ttl: 0},
+	}
+
+	for _, bm := range benchmarks {
+		b.Run(bm.name, func(b *testing.B) {
+			cfg := newTestConfig(b)
+			cfg.Features.UptimeCacheTTL = config.Duration(bm.ttl)
+			hh := NewHealthHandler(cfg, services.NewTaskService(cfg), newTestLogger())
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/health", nil)
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				rec := httptest.NewRecorder()
+				hh.HealthCheck(rec, req)
+				if rec.Code != http.StatusOK {
+					b.Fatalf("HealthCheck() status = %d, want 200", rec.Code)
+				}
+			}
+		})
+	}
+}
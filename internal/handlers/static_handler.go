@@ -1,7 +1,12 @@
 package handlers
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"html"
 	"net/http"
+	"strings"
 
 	"merge-queue/internal/config"
 	"merge-queue/pkg/utils"
@@ -21,11 +26,22 @@ func NewStaticHandler(cfg *config.Config, logger *utils.Logger) *StaticHandler {
 	}
 }
 
-// ServeHome handles GET / requests with a simple web interface.
+// ServeHome handles GET / requests with a simple web interface. The page
+// carries an ETag computed from its HTML, so browsers can revalidate it
+// once features.home_max_age has passed and get a 304 if it is unchanged.
 func (sh *StaticHandler) ServeHome(w http.ResponseWriter, r *http.Request) {
 	sh.logger.Debug("Serving home page")
 
-	html := `<!DOCTYPE html>
+	apiRoot := sh.config.Server.BasePath + "/api/v1"
+
+	banner := ""
+	if sh.config.App.Banner != "" {
+		banner = `
+        <div class="banner">📢 ` + html.EscapeString(sh.config.App.Banner) + `</div>
+`
+	}
+
+	page := `<!DOCTYPE html>
 <html lang="en">
 <head>
     <meta charset="UTF-8">
@@ -69,6 +85,15 @@ func (sh *StaticHandler) ServeHome(w http.ResponseWriter, r *http.Request) {
             opacity: 0.9;
         }
 
+        .banner {
+            background: #fff3cd;
+            color: #856404;
+            border-left: 4px solid #ffc107;
+            border-radius: 8px;
+            padding: 1rem 1.5rem;
+            margin-bottom: 2rem;
+        }
+
         .card {
             background: white;
             border-radius: 12px;
@@ -206,7 +231,7 @@ func (sh *StaticHandler) ServeHome(w http.ResponseWriter, r *http.Request) {
                 </div>
             </div>
         </div>
-
+` + banner + `
         <div class="card">
             <h2>🌟 Features</h2>
             <div class="features">
@@ -237,27 +262,27 @@ func (sh *StaticHandler) ServeHome(w http.ResponseWriter, r *http.Request) {
             <h2>📋 API Endpoints</h2>
             <div class="endpoints">
                 <div class="endpoint">
-                    <h3><span class="method get">GET</span>/api/v1/health</h3>
+                    <h3><span class="method get">GET</span>` + apiRoot + `/health</h3>
                     <p>Health check endpoint for monitoring</p>
                 </div>
                 <div class="endpoint">
-                    <h3><span class="method get">GET</span>/api/v1/tasks</h3>
+                    <h3><span class="method get">GET</span>` + apiRoot + `/tasks</h3>
                     <p>Get all tasks with optional filtering (?status=pending)</p>
                 </div>
                 <div class="endpoint">
-                    <h3><span class="method post">POST</span>/api/v1/tasks</h3>
+                    <h3><span class="method post">POST</span>` + apiRoot + `/tasks</h3>
                     <p>Create a new task with title, description, etc.</p>
                 </div>
                 <div class="endpoint">
-                    <h3><span class="method get">GET</span>/api/v1/tasks/{id}</h3>
+                    <h3><span class="method get">GET</span>` + apiRoot + `/tasks/{id}</h3>
                     <p>Get a specific task by ID</p>
                 </div>
                 <div class="endpoint">
-                    <h3><span class="method put">PUT</span>/api/v1/tasks/{id}</h3>
+                    <h3><span class="method put">PUT</span>` + apiRoot + `/tasks/{id}</h3>
                     <p>Update an existing task</p>
                 </div>
                 <div class="endpoint">
-                    <h3><span class="method delete">DELETE</span>/api/v1/tasks/{id}</h3>
+                    <h3><span class="method delete">DELETE</span>` + apiRoot + `/tasks/{id}</h3>
                     <p>Delete a task by ID</p>
                 </div>
             </div>
@@ -267,9 +292,9 @@ func (sh *StaticHandler) ServeHome(w http.ResponseWriter, r *http.Request) {
             <h3>🧪 Quick Test Commands</h3>
             <p>Try these commands in your terminal:</p>
 
-            <div class="code">curl http://localhost` + sh.config.Server.Port + `/api/v1/health</div>
-            <div class="code">curl http://localhost` + sh.config.Server.Port + `/api/v1/tasks</div>
-            <div class="code">curl -X POST http://localhost` + sh.config.Server.Port + `/api/v1/tasks \
+            <div class="code">curl http://localhost` + sh.config.Server.Port + apiRoot + `/health</div>
+            <div class="code">curl http://localhost` + sh.config.Server.Port + apiRoot + `/tasks</div>
+            <div class="code">curl -X POST http://localhost` + sh.config.Server.Port + apiRoot + `/tasks \
   -H "Content-Type: application/json" \
   -d '{"title":"Test Task","description":"Created from curl"}'</div>
         </div>
@@ -277,7 +302,36 @@ func (sh *StaticHandler) ServeHome(w http.ResponseWriter, r *http.Request) {
 </body>
 </html>`
 
+	// Weak, since the compression middleware may re-encode the body.
+	sum := sha256.Sum256([]byte(page))
+	etag := `W/"` + hex.EncodeToString(sum[:16]) + `"`
+
+	w.Header().Set("ETag", etag)
+	if maxAge := sh.config.Features.HomeMaxAge; maxAge > 0 {
+		w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", maxAge))
+	} else {
+		w.Header().Set("Cache-Control", "no-cache")
+	}
+
+	if match := r.Header.Get("If-None-Match"); match != "" && etagMatches(match, etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
 	w.WriteHeader(http.StatusOK)
-	w.Write([]byte(html))
+	w.Write([]byte(page))
+}
+
+// etagMatches reports whether an If-None-Match header value lists etag or
+// is "*". Comparison is weak, so W/ prefixes are ignored.
+func etagMatches(ifNoneMatch, etag string) bool {
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
 }
@@ -1,9 +1,17 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io"
 	"net/http"
+	"reflect"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gorilla/mux"
 
@@ -12,11 +20,19 @@ import (
 	"merge-queue/pkg/utils"
 )
 
+// Long-poll limits for GET /tasks/poll.
+const (
+	defaultPollWait = 30 * time.Second
+	maxPollWait     = 60 * time.Second
+	pollWriteGrace  = 10 * time.Second // Time allowed to write the response once the wait ends.
+)
+
 // TaskHandler handles HTTP requests for task operations.
 type TaskHandler struct {
 	taskService *services.TaskService
 	response    *utils.ResponseHelper
 	validator   *utils.ValidationUtils
+	timeUtils   *utils.TimeUtils
 	logger      *utils.Logger
 }
 
@@ -24,54 +40,169 @@ type TaskHandler struct {
 func NewTaskHandler(taskService *services.TaskService, logger *utils.Logger) *TaskHandler {
 	return &TaskHandler{
 		taskService: taskService,
-		response:    utils.NewResponseHelper(),
+		response:    utils.NewResponseHelperWithLogger(logger),
 		validator:   utils.NewValidationUtils(),
+		timeUtils:   utils.NewTimeUtils(),
 		logger:      logger,
 	}
 }
 
 // GetTasks handles GET /tasks requests.
 func (th *TaskHandler) GetTasks(w http.ResponseWriter, r *http.Request) {
-	th.logger.Debug("Getting tasks with filters")
+	th.loggerFor(r).Debug("Getting tasks with filters")
 
-	// Parse query parameters for filtering.
-	filter := &models.TaskFilter{
-		Status:     r.URL.Query().Get("status"),
-		Priority:   r.URL.Query().Get("priority"),
-		AssignedTo: r.URL.Query().Get("assigned_to"),
+	filter, err := th.parseTaskFilter(r)
+	if err != nil {
+		th.responseFor(r).SendError(w, http.StatusBadRequest, err.Error())
+		return
 	}
 
-	// Parse pagination parameters.
-	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
-		if limit, err := strconv.Atoi(limitStr); err == nil && limit > 0 {
-			filter.Limit = limit
-		}
+	if th.listNotModified(w, r, filter) {
+		return
 	}
 
-	if offsetStr := r.URL.Query().Get("offset"); offsetStr != "" {
-		if offset, err := strconv.Atoi(offsetStr); err == nil && offset >= 0 {
-			filter.Offset = offset
+	tasks, err := th.taskService.GetAllTasks(filter)
+	if err != nil {
+		th.loggerFor(r).Error("Failed to get tasks: %v", err)
+		th.responseFor(r).SendInternalError(w, "Failed to retrieve tasks", err)
+		return
+	}
+
+	total := th.taskService.Count(filter)
+	th.responseFor(r).SetTotalCount(w, total)
+
+	// The same URL serves JSON or CSV depending on the Accept header.
+	w.Header().Add("Vary", "Accept")
+	if utils.AcceptsCSV(r) {
+		if cursor := th.taskService.NextCursor(tasks, filter.Limit); cursor != "" {
+			w.Header().Set("X-Next-Cursor", cursor)
 		}
+		th.responseFor(r).SendTasksCSV(w, tasks)
+		return
 	}
 
-	// Parse tags filter.
-	if tagsStr := r.URL.Query().Get("tags"); tagsStr != "" {
-		filter.Tags = []string{tagsStr} // Simple implementation - could support multiple tags.
+	response := map[string]interface{}{
+		"tasks": th.presentAll(r, tasks),
+		"count": len(tasks),
+		"pagination": map[string]interface{}{
+			"limit":  filter.Limit,
+			"offset": filter.Offset,
+			"total":  total,
+		},
+	}
+	if cursor := th.taskService.NextCursor(tasks, filter.Limit); cursor != "" {
+		response["next_cursor"] = cursor
+	}
+
+	th.responseFor(r).SendSuccess(w, response)
+}
+
+// GetTaskIDs handles GET /tasks/ids requests.
+func (th *TaskHandler) GetTaskIDs(w http.ResponseWriter, r *http.Request) {
+	th.loggerFor(r).Debug("Getting task IDs with filters")
+
+	filter, err := th.parseTaskFilter(r)
+	if err != nil {
+		th.responseFor(r).SendError(w, http.StatusBadRequest, err.Error())
+		return
 	}
 
 	tasks, err := th.taskService.GetAllTasks(filter)
 	if err != nil {
-		th.logger.Error("Failed to get tasks: %v", err)
-		th.response.SendError(w, http.StatusInternalServerError, "Failed to retrieve tasks")
+		th.loggerFor(r).Error("Failed to get task IDs: %v", err)
+		th.responseFor(r).SendInternalError(w, "Failed to retrieve tasks", err)
 		return
 	}
 
+	ids := make([]int, 0, len(tasks))
+	for _, task := range tasks {
+		ids = append(ids, task.ID)
+	}
+
+	th.responseFor(r).SetTotalCount(w, th.taskService.Count(filter))
+
 	response := map[string]interface{}{
-		"tasks": tasks,
-		"count": len(tasks),
+		"ids":   ids,
+		"count": len(ids),
+	}
+	if cursor := th.taskService.NextCursor(tasks, filter.Limit); cursor != "" {
+		response["next_cursor"] = cursor
+	}
+
+	th.responseFor(r).SendSuccess(w, response)
+}
+
+// GetTaskChanges handles GET /tasks/changes?since=<rfc3339> requests.
+func (th *TaskHandler) GetTaskChanges(w http.ResponseWriter, r *http.Request) {
+	since, err := th.timeUtils.ParseTimestamp(r.URL.Query().Get("since"))
+	if err != nil {
+		th.responseFor(r).SendError(w, http.StatusBadRequest, fmt.Sprintf("since: %v", err))
+		return
+	}
+
+	tasks, serverTime := th.taskService.ChangesSince(since)
+
+	response := map[string]interface{}{
+		"tasks":       th.presentAll(r, tasks),
+		"count":       len(tasks),
+		"server_time": serverTime,
 	}
 
-	th.response.SendSuccess(w, response)
+	th.responseFor(r).SendSuccess(w, response)
+}
+
+// PollTaskChanges handles GET /tasks/poll?since=<rfc3339>&wait=30s requests.
+// It answers like GetTaskChanges, but when nothing has changed it holds the
+// request open for up to wait before returning an empty set.
+func (th *TaskHandler) PollTaskChanges(w http.ResponseWriter, r *http.Request) {
+	since, err := th.timeUtils.ParseTimestamp(r.URL.Query().Get("since"))
+	if err != nil {
+		th.responseFor(r).SendError(w, http.StatusBadRequest, fmt.Sprintf("since: %v", err))
+		return
+	}
+
+	wait, err := parsePollWait(r.URL.Query().Get("wait"))
+	if err != nil {
+		th.responseFor(r).SendError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// The server's write timeout may be shorter than the wait; extend it for
+	// this request so the response can still be sent.
+	if err := http.NewResponseController(w).SetWriteDeadline(time.Now().Add(wait + pollWriteGrace)); err != nil {
+		th.loggerFor(r).Debug("Could not extend write deadline for poll: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), wait)
+	defer cancel()
+
+	tasks, serverTime := th.taskService.WaitForChanges(ctx, since)
+
+	response := map[string]interface{}{
+		"tasks":       th.presentAll(r, tasks),
+		"count":       len(tasks),
+		"server_time": serverTime,
+		"timed_out":   len(tasks) == 0,
+	}
+
+	th.responseFor(r).SendSuccess(w, response)
+}
+
+// CountTasks handles GET /tasks/count requests.
+func (th *TaskHandler) CountTasks(w http.ResponseWriter, r *http.Request) {
+	th.loggerFor(r).Debug("Counting tasks with filters")
+
+	filter, err := th.parseTaskFilter(r)
+	if err != nil {
+		th.responseFor(r).SendError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	response := map[string]interface{}{
+		"count": th.taskService.Count(filter),
+	}
+
+	th.responseFor(r).SendSuccess(w, response)
 }
 
 // GetTask handles GET /tasks/{id} requests.
@@ -79,87 +210,205 @@ func (th *TaskHandler) GetTask(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	idStr, exists := vars["id"]
 	if !exists {
-		th.response.SendError(w, http.StatusBadRequest, "Task ID is required")
+		th.responseFor(r).SendError(w, http.StatusBadRequest, "Task ID is required")
 		return
 	}
 
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		th.response.SendError(w, http.StatusBadRequest, "Invalid task ID")
+		th.responseFor(r).SendError(w, http.StatusBadRequest, "Invalid task ID")
 		return
 	}
 
-	th.logger.Debug("Getting task with ID: %d", id)
+	th.loggerFor(r).Debug("Getting task with ID: %d", id)
 
 	task, err := th.taskService.GetTask(id)
 	if err != nil {
-		th.logger.Warn("Task not found: %d", id)
-		th.response.SendError(w, http.StatusNotFound, "Task not found")
+		th.sendTaskNotFound(w, r, id)
+		return
+	}
+
+	th.responseFor(r).SendSuccess(w, th.present(r, task))
+}
+
+// GetTaskAge handles GET /tasks/{id}/age requests.
+func (th *TaskHandler) GetTaskAge(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		th.responseFor(r).SendError(w, http.StatusBadRequest, "Invalid task ID")
+		return
+	}
+
+	age, err := th.taskService.GetTaskAge(id)
+	if err != nil {
+		th.sendTaskNotFound(w, r, id)
 		return
 	}
 
-	th.response.SendSuccess(w, task)
+	th.responseFor(r).SendSuccess(w, age)
 }
 
 // CreateTask handles POST /tasks requests.
 func (th *TaskHandler) CreateTask(w http.ResponseWriter, r *http.Request) {
-	th.logger.Debug("Creating new task")
+	th.loggerFor(r).Debug("Creating new task")
 
 	var req models.CreateTaskRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		th.response.SendError(w, http.StatusBadRequest, "Invalid JSON format")
+	if !th.decodeBody(w, r, &req, "Invalid JSON format") {
 		return
 	}
 
 	// Basic validation.
 	if th.validator.IsEmpty(req.Title) {
-		th.response.SendError(w, http.StatusBadRequest, "Task title is required")
+		th.responseFor(r).SendError(w, http.StatusBadRequest, "Task title is required")
+		return
+	}
+
+	if th.isDryRun(r) {
+		task, err := th.taskService.PreviewTask(&req)
+		if err != nil {
+			th.responseFor(r).SendError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		th.loggerFor(r).Debug("Dry run: task %q passed validation", task.Title)
+		th.responseFor(r).SendSuccess(w, map[string]interface{}{
+			"dry_run": true,
+			"task":    th.present(r, task),
+		})
 		return
 	}
 
 	task, err := th.taskService.CreateTask(&req)
 	if err != nil {
-		th.logger.Error("Failed to create task: %v", err)
-		th.response.SendError(w, http.StatusBadRequest, err.Error())
+		th.sendWriteError(w, r, err)
 		return
 	}
 
-	th.logger.Info("Created task with ID: %d", task.ID)
-	th.response.SendCreated(w, task)
+	th.loggerFor(r).Info("Created task with ID: %d", task.ID)
+	th.responseFor(r).SendCreated(w, th.present(r, task))
 }
 
-// UpdateTask handles PUT /tasks/{id} requests.
+// EnsureTaskByTitle handles PUT /tasks/by-title/{title} requests. It
+// returns the existing task with that title, or creates one from the
+// optional body and responds 201.
+func (th *TaskHandler) EnsureTaskByTitle(w http.ResponseWriter, r *http.Request) {
+	var req models.CreateTaskRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+		th.responseFor(r).SendError(w, http.StatusBadRequest, describeJSONError(err, "Invalid JSON format"))
+		return
+	}
+	req.Title = mux.Vars(r)["title"]
+
+	if th.validator.IsEmpty(req.Title) {
+		th.responseFor(r).SendError(w, http.StatusBadRequest, "Task title is required")
+		return
+	}
+
+	task, created, err := th.taskService.EnsureTask(&req)
+	if err != nil {
+		th.sendWriteError(w, r, err)
+		return
+	}
+
+	if !created {
+		th.loggerFor(r).Debug("Task %q already exists with ID: %d", task.Title, task.ID)
+		th.responseFor(r).SendSuccess(w, th.present(r, task))
+		return
+	}
+
+	th.loggerFor(r).Info("Created task with ID: %d", task.ID)
+	th.responseFor(r).SendCreated(w, th.present(r, task))
+}
+
+// ValidateTask handles POST /tasks/validate requests.
+func (th *TaskHandler) ValidateTask(w http.ResponseWriter, r *http.Request) {
+	th.loggerFor(r).Debug("Validating task")
+
+	var req models.CreateTaskRequest
+	if !th.decodeBody(w, r, &req, "Invalid JSON format") {
+		return
+	}
+
+	th.responseFor(r).SendSuccess(w, th.taskService.ValidateTask(&req))
+}
+
+// UpdateTask handles PUT /tasks/{id} requests, replacing the task's fields.
 func (th *TaskHandler) UpdateTask(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	idStr, exists := vars["id"]
 	if !exists {
-		th.response.SendError(w, http.StatusBadRequest, "Task ID is required")
+		th.responseFor(r).SendError(w, http.StatusBadRequest, "Task ID is required")
+		return
+	}
+
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		th.responseFor(r).SendError(w, http.StatusBadRequest, "Invalid task ID")
+		return
+	}
+
+	th.loggerFor(r).Debug("Replacing task with ID: %d", id)
+
+	var req models.UpdateTaskRequest
+	if !th.decodeBody(w, r, &req, "Invalid JSON format") {
+		return
+	}
+
+	task, err := th.taskService.ReplaceTask(id, &req)
+	if errors.Is(err, services.ErrTaskNotFound) {
+		th.sendTaskNotFound(w, r, id)
+		return
+	}
+	if err != nil {
+		th.sendWriteError(w, r, err)
+		return
+	}
+
+	th.loggerFor(r).Info("Replaced task with ID: %d", task.ID)
+	th.responseFor(r).SendSuccess(w, th.present(r, task))
+}
+
+// PatchTask handles PATCH /tasks/{id} requests. Only the fields present in
+// the body change; a JSON Patch document is applied instead when sent as
+// application/json-patch+json.
+func (th *TaskHandler) PatchTask(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	idStr, exists := vars["id"]
+	if !exists {
+		th.responseFor(r).SendError(w, http.StatusBadRequest, "Task ID is required")
 		return
 	}
 
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		th.response.SendError(w, http.StatusBadRequest, "Invalid task ID")
+		th.responseFor(r).SendError(w, http.StatusBadRequest, "Invalid task ID")
 		return
 	}
 
-	th.logger.Debug("Updating task with ID: %d", id)
+	th.loggerFor(r).Debug("Updating task with ID: %d", id)
+
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "application/json-patch+json") {
+		th.applyJSONPatch(w, r, id)
+		return
+	}
 
 	var req models.UpdateTaskRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		th.response.SendError(w, http.StatusBadRequest, "Invalid JSON format")
+	if !th.decodeBody(w, r, &req, "Invalid JSON format") {
 		return
 	}
 
 	task, err := th.taskService.UpdateTask(id, &req)
+	if errors.Is(err, services.ErrTaskNotFound) {
+		th.sendTaskNotFound(w, r, id)
+		return
+	}
 	if err != nil {
-		th.logger.Error("Failed to update task %d: %v", id, err)
-		th.response.SendError(w, http.StatusBadRequest, err.Error())
+		th.sendWriteError(w, r, err)
 		return
 	}
 
-	th.logger.Info("Updated task with ID: %d", task.ID)
-	th.response.SendSuccess(w, task)
+	th.loggerFor(r).Info("Updated task with ID: %d", task.ID)
+	th.responseFor(r).SendSuccess(w, th.present(r, task))
 }
 
 // DeleteTask handles DELETE /tasks/{id} requests.
@@ -167,58 +416,502 @@ func (th *TaskHandler) DeleteTask(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	idStr, exists := vars["id"]
 	if !exists {
-		th.response.SendError(w, http.StatusBadRequest, "Task ID is required")
+		th.responseFor(r).SendError(w, http.StatusBadRequest, "Task ID is required")
 		return
 	}
 
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		th.response.SendError(w, http.StatusBadRequest, "Invalid task ID")
+		th.responseFor(r).SendError(w, http.StatusBadRequest, "Invalid task ID")
 		return
 	}
 
-	th.logger.Debug("Deleting task with ID: %d", id)
+	th.loggerFor(r).Debug("Deleting task with ID: %d", id)
 
-	if err := th.taskService.DeleteTask(id); err != nil {
-		th.logger.Error("Failed to delete task %d: %v", id, err)
-		th.response.SendError(w, http.StatusNotFound, "Task not found")
+	err = th.taskService.DeleteTask(id)
+	if errors.Is(err, services.ErrTaskNotFound) {
+		th.sendTaskNotFound(w, r, id)
+		return
+	}
+	if err != nil {
+		th.sendWriteError(w, r, err)
 		return
 	}
 
-	th.logger.Info("Deleted task with ID: %d", id)
-	th.response.SendNoContent(w)
+	th.loggerFor(r).Info("Deleted task with ID: %d", id)
+	th.responseFor(r).SendNoContent(w)
 }
 
 // SearchTasks handles POST /tasks/search requests.
 func (th *TaskHandler) SearchTasks(w http.ResponseWriter, r *http.Request) {
-	th.logger.Debug("Searching tasks")
+	th.loggerFor(r).Debug("Searching tasks")
 
 	var query models.TaskSearchQuery
-	if err := json.NewDecoder(r.Body).Decode(&query); err != nil {
-		th.response.SendError(w, http.StatusBadRequest, "Invalid JSON format")
+	if !th.decodeBody(w, r, &query, "Invalid JSON format") {
 		return
 	}
 
-	tasks, err := th.taskService.SearchTasks(&query)
+	if err := th.taskService.ValidateSearchQuery(&query); err != nil {
+		th.responseFor(r).SendError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	result, err := th.taskService.SearchTasks(&query)
 	if err != nil {
-		th.logger.Error("Failed to search tasks: %v", err)
-		th.response.SendError(w, http.StatusInternalServerError, "Failed to search tasks")
+		th.loggerFor(r).Error("Failed to search tasks: %v", err)
+		th.responseFor(r).SendInternalError(w, "Failed to search tasks", err)
 		return
 	}
 
+	th.responseFor(r).SetTotalCount(w, result.Total)
+
 	response := map[string]interface{}{
-		"tasks": tasks,
-		"count": len(tasks),
-		"query": query.Query,
+		"tasks":     th.presentAll(r, result.Tasks),
+		"count":     len(result.Tasks),
+		"total":     result.Total,
+		"query":     query.Query,
+		"matches":   result.Matches,
+		"truncated": result.Truncated,
 	}
 
-	th.response.SendSuccess(w, response)
+	th.responseFor(r).SendSuccess(w, response)
+}
+
+// BulkUpdatePriority handles POST /tasks/priority requests, setting one
+// priority on several tasks. Unknown IDs are reported per task.
+func (th *TaskHandler) BulkUpdatePriority(w http.ResponseWriter, r *http.Request) {
+	var req models.BulkPriorityRequest
+	if !th.decodeBody(w, r, &req, "Invalid JSON format") {
+		return
+	}
+
+	results, err := th.taskService.BulkUpdatePriority(req.TaskIDs, req.Priority)
+	if err != nil {
+		th.sendWriteError(w, r, err)
+		return
+	}
+
+	updated := 0
+	for _, result := range results {
+		if result.Updated {
+			updated++
+		}
+	}
+
+	th.loggerFor(r).Info("Set priority on %d of %d tasks", updated, len(results))
+
+	response := map[string]interface{}{
+		"results": results,
+		"updated": updated,
+		"failed":  len(results) - updated,
+	}
+	th.responseFor(r).SendSuccess(w, response)
 }
 
 // GetTaskStats handles GET /tasks/stats requests.
 func (th *TaskHandler) GetTaskStats(w http.ResponseWriter, r *http.Request) {
-	th.logger.Debug("Getting task statistics")
+	th.loggerFor(r).Debug("Getting task statistics")
+
+	opts, err := parseStatsOptions(r)
+	if err != nil {
+		th.responseFor(r).SendError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	stats := th.taskService.GetTaskStats(opts)
+	th.responseFor(r).SendSuccess(w, stats)
+}
+
+// GetTaskStatsMetrics handles GET /tasks/stats/metrics requests, rendering
+// the task statistics in the Prometheus text format. It does not depend on
+// features.enable_metrics.
+func (th *TaskHandler) GetTaskStatsMetrics(w http.ResponseWriter, r *http.Request) {
+	opts, err := parseStatsOptions(r)
+	if err != nil {
+		th.responseFor(r).SendError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	th.responseFor(r).SendTaskStatsMetrics(w, th.taskService.GetTaskStats(opts))
+}
+
+// GetTaskSummary handles GET /tasks/summary requests.
+func (th *TaskHandler) GetTaskSummary(w http.ResponseWriter, r *http.Request) {
+	recentLimit, tagLimit := 5, 10
+	params := map[string]*int{
+		"recent_limit": &recentLimit,
+		"tag_limit":    &tagLimit,
+	}
+	for name, target := range params {
+		value := r.URL.Query().Get(name)
+		if value == "" {
+			continue
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 || n > 100 {
+			th.responseFor(r).SendErrorf(w, http.StatusBadRequest, "%s must be an integer between 1 and 100", name)
+			return
+		}
+		*target = n
+	}
+
+	th.responseFor(r).SendSuccess(w, th.taskService.GetSummary(recentLimit, tagLimit))
+}
+
+// GetTagCounts handles GET /tasks/tags requests.
+func (th *TaskHandler) GetTagCounts(w http.ResponseWriter, r *http.Request) {
+	var limit, minCount int
+	params := map[string]*int{
+		"limit":     &limit,
+		"min_count": &minCount,
+	}
+	for name, target := range params {
+		value := r.URL.Query().Get(name)
+		if value == "" {
+			continue
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			th.responseFor(r).SendErrorf(w, http.StatusBadRequest, "%s must be a non-negative integer", name)
+			return
+		}
+		*target = n
+	}
+
+	tags := th.taskService.GetTagCounts(limit, minCount)
+
+	response := map[string]interface{}{
+		"tags":  tags,
+		"count": len(tags),
+	}
+
+	th.responseFor(r).SendSuccess(w, response)
+}
+
+// Helper methods.
+
+// applyJSONPatch applies a JSON Patch document to the task with the given ID.
+func (th *TaskHandler) applyJSONPatch(w http.ResponseWriter, r *http.Request, id int) {
+	var ops []models.JSONPatchOperation
+	if !th.decodeBody(w, r, &ops, "Invalid JSON Patch document") {
+		return
+	}
+
+	task, err := th.taskService.PatchTask(id, ops)
+	if errors.Is(err, services.ErrTaskNotFound) {
+		th.sendTaskNotFound(w, r, id)
+		return
+	}
+	if err != nil {
+		th.sendWriteError(w, r, err)
+		return
+	}
+
+	th.loggerFor(r).Info("Patched task with ID: %d", task.ID)
+	th.responseFor(r).SendSuccess(w, th.present(r, task))
+}
+
+// present returns task as it should be serialized for r: with derived
+// fields when the request asks for ?include=derived, otherwise unchanged.
+func (th *TaskHandler) present(r *http.Request, task *models.Task) interface{} {
+	if !th.includes(r, "derived") {
+		return task
+	}
+	return th.taskView(task)
+}
+
+// presentAll is present for a list of tasks.
+func (th *TaskHandler) presentAll(r *http.Request, tasks []*models.Task) interface{} {
+	if !th.includes(r, "derived") {
+		return tasks
+	}
+
+	views := make([]*models.TaskView, 0, len(tasks))
+	for _, task := range tasks {
+		views = append(views, th.taskView(task))
+	}
+	return views
+}
+
+// taskView computes the derived fields for task.
+func (th *TaskHandler) taskView(task *models.Task) *models.TaskView {
+	return &models.TaskView{
+		Task:               task,
+		AgeHuman:           th.timeUtils.FormatRelativeTime(task.CreatedAt),
+		Overdue:            task.IsOverdue(time.Now()),
+		AllowedTransitions: models.GetAllowedTransitions(task.Status),
+	}
+}
+
+// includes reports whether the comma-separated include parameter names
+// the given option.
+func (th *TaskHandler) includes(r *http.Request, option string) bool {
+	for _, value := range strings.Split(r.URL.Query().Get("include"), ",") {
+		if strings.TrimSpace(value) == option {
+			return true
+		}
+	}
+	return false
+}
+
+// sendTaskNotFound sends the 404 body shared by every endpoint that looks
+// up a task by ID.
+func (th *TaskHandler) sendTaskNotFound(w http.ResponseWriter, r *http.Request, id int) {
+	th.responseFor(r).SendErrorWithCode(w, http.StatusNotFound, "TASK_NOT_FOUND", "Task not found", fmt.Sprintf("No task exists with ID %d", id))
+}
+
+// sendWriteError answers a failed create, update or delete: 500 when the
+// task store rejected the change, otherwise 400 with the validation error.
+func (th *TaskHandler) sendWriteError(w http.ResponseWriter, r *http.Request, err error) {
+	if errors.Is(err, services.ErrPersist) {
+		th.loggerFor(r).Error("Failed to save tasks: %v", err)
+		th.responseFor(r).SendInternalError(w, "Failed to save tasks", err)
+		return
+	}
+	th.responseFor(r).SendError(w, http.StatusBadRequest, err.Error())
+}
+
+// decodeBody decodes the JSON request body into v. A missing or
+// malformed body is answered with a 400 and false is returned; an empty
+// body gets its own BODY_REQUIRED error rather than a decoder message.
+func (th *TaskHandler) decodeBody(w http.ResponseWriter, r *http.Request, v interface{}, fallback string) bool {
+	err := json.NewDecoder(r.Body).Decode(v)
+	if err == nil {
+		return true
+	}
+
+	if errors.Is(err, io.EOF) {
+		th.responseFor(r).SendErrorWithCode(w, http.StatusBadRequest, "BODY_REQUIRED", "Request body is required", "Send a JSON document in the request body")
+		return false
+	}
+
+	th.responseFor(r).SendError(w, http.StatusBadRequest, describeJSONError(err, fallback))
+	return false
+}
+
+// describeJSONError turns a decoding error into a client-facing message.
+// Type mismatches name the offending field and the expected type; any
+// other error yields fallback.
+func describeJSONError(err error, fallback string) string {
+	var typeErr *json.UnmarshalTypeError
+	if !errors.As(err, &typeErr) || typeErr.Field == "" {
+		return fallback
+	}
+
+	return fmt.Sprintf("%s must be %s", typeErr.Field, describeJSONType(typeErr.Type))
+}
+
+// describeJSONType names a Go type in JSON terms, e.g. "an array of strings".
+func describeJSONType(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.String:
+		return "a string"
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "an integer"
+	case reflect.Float32, reflect.Float64:
+		return "a number"
+	case reflect.Slice, reflect.Array:
+		return "an array of " + strings.TrimPrefix(strings.TrimPrefix(describeJSONType(t.Elem()), "an "), "a ") + "s"
+	case reflect.Map, reflect.Struct:
+		return "an object"
+	case reflect.Ptr:
+		return describeJSONType(t.Elem())
+	default:
+		return "a " + t.String()
+	}
+}
+
+// responseFor returns a response helper that logs with the request's logger.
+func (th *TaskHandler) responseFor(r *http.Request) *utils.ResponseHelper {
+	return th.response.WithRequest(r)
+}
+
+// loggerFor returns the request-scoped logger, falling back to the shared one.
+func (th *TaskHandler) loggerFor(r *http.Request) *utils.Logger {
+	return utils.LoggerFromContext(r.Context(), th.logger)
+}
+
+// isDryRun reports whether the request asks for validation only, via
+// ?dry_run=true or the X-Dry-Run header.
+func (th *TaskHandler) isDryRun(r *http.Request) bool {
+	value := r.URL.Query().Get("dry_run")
+	if value == "" {
+		value = r.Header.Get("X-Dry-Run")
+	}
+
+	dryRun, err := strconv.ParseBool(value)
+	return err == nil && dryRun
+}
+
+// parseStatsOptions reads the top_users and min_user_tasks query parameters.
+func parseStatsOptions(r *http.Request) (models.TaskStatsOptions, error) {
+	var opts models.TaskStatsOptions
+
+	params := map[string]*int{
+		"top_users":      &opts.TopUsers,
+		"min_user_tasks": &opts.MinUserTasks,
+	}
+	for name, target := range params {
+		value := r.URL.Query().Get(name)
+		if value == "" {
+			continue
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return opts, fmt.Errorf("%s must be a non-negative integer", name)
+		}
+		*target = n
+	}
+
+	return opts, nil
+}
+
+// listNotModified sets ETag and Last-Modified for a task listing and, if
+// the client's copy is still current, answers 304 and reports true. The
+// weak ETag combines the match count and last change with a hash of the
+// query and Accept header, so each filter and format is cached separately.
+// If-None-Match takes precedence over If-Modified-Since.
+func (th *TaskHandler) listNotModified(w http.ResponseWriter, r *http.Request, filter *models.TaskFilter) bool {
+	count, lastModified := th.taskService.ListVersion(filter)
+
+	variant := fnv.New64a()
+	variant.Write([]byte(r.URL.Query().Encode()))
+	variant.Write([]byte{0})
+	variant.Write([]byte(r.Header.Get("Accept")))
+	etag := fmt.Sprintf(`W/"%d-%d-%x"`, count, lastModified.UnixNano(), variant.Sum64())
+
+	w.Header().Set("ETag", etag)
+	if !lastModified.IsZero() {
+		w.Header().Set("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+	}
+
+	notModified := false
+	if match := r.Header.Get("If-None-Match"); match != "" {
+		notModified = etagMatches(match, etag)
+	} else if since, err := http.ParseTime(r.Header.Get("If-Modified-Since")); err == nil && !lastModified.IsZero() {
+		// Last-Modified has one-second precision.
+		notModified = !lastModified.Truncate(time.Second).After(since)
+	}
+
+	if notModified {
+		w.WriteHeader(http.StatusNotModified)
+	}
+	return notModified
+}
+
+// parsePollWait parses the wait parameter of a poll, given as a duration
+// ("30s") or whole seconds ("30"). Empty means defaultPollWait.
+func parsePollWait(value string) (time.Duration, error) {
+	if value == "" {
+		return defaultPollWait, nil
+	}
+
+	wait, err := time.ParseDuration(value)
+	if err != nil {
+		seconds, convErr := strconv.Atoi(value)
+		if convErr != nil {
+			return 0, fmt.Errorf("wait must be a duration such as 30s")
+		}
+		wait = time.Duration(seconds) * time.Second
+	}
+
+	if wait < 0 || wait > maxPollWait {
+		return 0, fmt.Errorf("wait must be between 0s and %v", maxPollWait)
+	}
+	return wait, nil
+}
+
+// parseTaskFilter builds a TaskFilter from the request's query parameters.
+func (th *TaskHandler) parseTaskFilter(r *http.Request) (*models.TaskFilter, error) {
+	// Parse query parameters for filtering.
+	filter := &models.TaskFilter{
+		Status:        r.URL.Query().Get("status"),
+		Priority:      r.URL.Query().Get("priority"),
+		AssignedTo:    r.URL.Query().Get("assigned_to"),
+		MinPriority:   r.URL.Query().Get("min_priority"),
+		MetaKey:       r.URL.Query().Get("meta_key"),
+		MetaValue:     r.URL.Query().Get("meta_value"),
+		CreatedIn:     r.URL.Query().Get("created_in"),
+		CreatedInWeek: r.URL.Query().Get("created_in_week"),
+		Age:           r.URL.Query().Get("age"),
+		Cursor:        r.URL.Query().Get("cursor"),
+	}
+
+	// Parse pagination parameters. per_page is accepted as an alias for
+	// limit; without either the configured page size applies, and an
+	// explicit 0 returns every match.
+	filter.Limit = th.taskService.DefaultPageSize()
+	limitStr := r.URL.Query().Get("limit")
+	if limitStr == "" {
+		limitStr = r.URL.Query().Get("per_page")
+	}
+	if limitStr != "" {
+		if limit, err := strconv.Atoi(limitStr); err == nil && limit >= 0 {
+			filter.Limit = limit
+		}
+	}
+
+	if offsetStr := r.URL.Query().Get("offset"); offsetStr != "" {
+		if offset, err := strconv.Atoi(offsetStr); err == nil && offset >= 0 {
+			filter.Offset = offset
+		}
+	}
+
+	if value := r.URL.Query().Get("due_after"); value != "" {
+		dueAfter, err := th.timeUtils.ParseTimestamp(value)
+		if err != nil {
+			return nil, fmt.Errorf("due_after: %v", err)
+		}
+		filter.DueAfter = &dueAfter
+	}
+
+	if value := r.URL.Query().Get("due_before"); value != "" {
+		dueBefore, err := th.timeUtils.ParseTimestamp(value)
+		if err != nil {
+			return nil, fmt.Errorf("due_before: %v", err)
+		}
+		filter.DueBefore = &dueBefore
+	}
+
+	if value := r.URL.Query().Get("has_due_date"); value != "" {
+		hasDueDate, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("has_due_date must be true or false")
+		}
+		filter.HasDueDate = &hasDueDate
+	}
+
+	if value := r.URL.Query().Get("include_completed"); value != "" {
+		include, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("include_completed must be true or false")
+		}
+		filter.IncludeCompleted = &include
+	}
+
+	// Parse tags filter.
+	if tagsStr := r.URL.Query().Get("tags"); tagsStr != "" {
+		filter.Tags = []string{tagsStr} // Simple implementation - could support multiple tags.
+	}
+
+	// Parse metadata filters given as metadata.<key>=<value>.
+	for param, values := range r.URL.Query() {
+		key := strings.TrimPrefix(param, "metadata.")
+		if key == param || key == "" || len(values) == 0 {
+			continue
+		}
+		if filter.Metadata == nil {
+			filter.Metadata = make(map[string]string)
+		}
+		filter.Metadata[key] = values[0]
+	}
+
+	if err := th.taskService.ValidateFilter(filter); err != nil {
+		return nil, err
+	}
 
-	stats := th.taskService.GetTaskStats()
-	th.response.SendSuccess(w, stats)
+	return filter, nil
 }
@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+
+	"merge-queue/internal/services"
+)
+
+// newTestRouter serves the task routes from an empty TaskService.
+func newTestRouter(t *testing.T) http.Handler {
+	t.Helper()
+
+	cfg := newTestConfig(t)
+	th := NewTaskHandler(services.NewTaskService(cfg), newTestLogger())
+
+	router := mux.NewRouter()
+	router.HandleFunc("/tasks/{id:[0-9]+}", th.GetTask).Methods("GET")
+	router.HandleFunc("/tasks/{id:[0-9]+}", th.UpdateTask).Methods("PUT")
+	router.HandleFunc("/tasks/{id:[0-9]+}", th.PatchTask).Methods("PATCH")
+	router.HandleFunc("/tasks/{id:[0-9]+}", th.DeleteTask).Methods("DELETE")
+	router.HandleFunc("/tasks/{id:[0-9]+}/age", th.GetTaskAge).Methods("GET")
+	return router
+}
+
+// timestampPattern matches the response timestamp, the only part of an
+// error body that varies between requests.
+var timestampPattern = regexp.MustCompile(`"timestamp":"[^"]*"`)
+
+func TestTaskNotFoundShape(t *testing.T) {
+	tests := []struct {
+		name        string
+		method      string
+		path        string
+		contentType string
+		body        string
+	}{
+		{name: "get", method: http.MethodGet, path: "/tasks/999"},
+		{name: "age", method: http.MethodGet, path: "/tasks/999/age"},
+		{name: "put", method: http.MethodPut, path: "/tasks/999", contentType: "application/json",
+			body: `{"title":"Replaced","status":"pending","priority":"low"}`},
+		{name: "patch", method: http.MethodPatch, path: "/tasks/999", contentType: "application/json",
+			body: `{"title":"Renamed"}`},
+		{name: "json patch", method: http.MethodPatch, path: "/tasks/999", contentType: "application/json-patch+json",
+			body: `[{"op":"replace","path":"/title","value":"Renamed"}]`},
+		{name: "delete", method: http.MethodDelete, path: "/tasks/999"},
+	}
+
+	router := newTestRouter(t)
+	var want string
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			if rec.Code != http.StatusNotFound {
+				t.Fatalf("status = %d, want 404; body %s", rec.Code, rec.Body.String())
+			}
+
+			got := timestampPattern.ReplaceAllString(rec.Body.String(), `"timestamp":""`)
+			if want == "" {
+				want = got
+				if !strings.Contains(want, `"code":"TASK_NOT_FOUND"`) {
+					t.Fatalf("body = %s, want a TASK_NOT_FOUND error", want)
+				}
+				return
+			}
+			if got != want {
+				t.Fatalf("body = %s\nwant %s", got, want)
+			}
+		})
+	}
+}
@@ -2,20 +2,27 @@ package middleware
 
 import (
 	"context"
+	"crypto/subtle"
 	"net/http"
 	"strings"
 
+	"merge-queue/internal/config"
+	"merge-queue/internal/models"
 	"merge-queue/pkg/utils"
 )
 
 // AuthMiddleware handles authentication (placeholder for future implementation).
 type AuthMiddleware struct {
+	config *config.Config
 	logger *utils.Logger
 }
 
 // NewAuthMiddleware creates a new auth middleware instance.
-func NewAuthMiddleware(logger *utils.Logger) *AuthMiddleware {
-	return &AuthMiddleware{logger: logger}
+func NewAuthMiddleware(cfg *config.Config, logger *utils.Logger) *AuthMiddleware {
+	return &AuthMiddleware{
+		config: cfg,
+		logger: logger,
+	}
 }
 
 // Handler returns the auth middleware handler.
@@ -31,7 +38,8 @@ func (am *AuthMiddleware) Handler(next http.Handler) http.Handler {
 			// TODO: Validate token and extract user information.
 			// For now, we'll just add a placeholder user to the context.
 			ctx := context.WithValue(r.Context(), "user_id", "anonymous")
-			ctx = context.WithValue(ctx, "user_role", "user")
+			ctx = context.WithValue(ctx, "user_role", tokenRole(am.config, token))
+			ctx = utils.ContextWithLogger(ctx, utils.LoggerFromContext(ctx, am.logger).With("user", "anonymous"))
 			r = r.WithContext(ctx)
 		}
 
@@ -41,15 +49,17 @@ func (am *AuthMiddleware) Handler(next http.Handler) http.Handler {
 
 // RequireAuthMiddleware requires authentication for protected routes.
 type RequireAuthMiddleware struct {
+	config   *config.Config
 	logger   *utils.Logger
 	response *utils.ResponseHelper
 }
 
 // NewRequireAuthMiddleware creates a middleware that requires authentication.
-func NewRequireAuthMiddleware(logger *utils.Logger) *RequireAuthMiddleware {
+func NewRequireAuthMiddleware(cfg *config.Config, logger *utils.Logger) *RequireAuthMiddleware {
 	return &RequireAuthMiddleware{
+		config:   cfg,
 		logger:   logger,
-		response: utils.NewResponseHelper(),
+		response: utils.NewResponseHelperWithLogger(logger),
 	}
 }
 
@@ -60,7 +70,7 @@ func (ram *RequireAuthMiddleware) Handler(next http.Handler) http.Handler {
 
 		if token == "" {
 			ram.logger.Warn("Unauthorized access attempt to %s from %s", r.URL.Path, r.RemoteAddr)
-			ram.response.SendError(w, http.StatusUnauthorized, "Authentication required")
+			ram.response.WithRequest(r).SendError(w, http.StatusUnauthorized, "Authentication required")
 			return
 		}
 
@@ -68,7 +78,8 @@ func (ram *RequireAuthMiddleware) Handler(next http.Handler) http.Handler {
 		// For now, we accept any non-empty token.
 
 		ctx := context.WithValue(r.Context(), "user_id", "authenticated_user")
-		ctx = context.WithValue(ctx, "user_role", "user")
+		ctx = context.WithValue(ctx, "user_role", tokenRole(ram.config, token))
+		ctx = utils.ContextWithLogger(ctx, utils.LoggerFromContext(ctx, ram.logger).With("user", "authenticated_user"))
 		r = r.WithContext(ctx)
 
 		next.ServeHTTP(w, r)
@@ -87,7 +98,7 @@ func NewRoleMiddleware(requiredRole string, logger *utils.Logger) *RoleMiddlewar
 	return &RoleMiddleware{
 		requiredRole: requiredRole,
 		logger:       logger,
-		response:     utils.NewResponseHelper(),
+		response:     utils.NewResponseHelperWithLogger(logger),
 	}
 }
 
@@ -97,13 +108,13 @@ func (rm *RoleMiddleware) Handler(next http.Handler) http.Handler {
 		userRole, ok := r.Context().Value("user_role").(string)
 		if !ok {
 			rm.logger.Warn("No user role found in context for %s", r.URL.Path)
-			rm.response.SendError(w, http.StatusForbidden, "Access denied")
+			rm.response.WithRequest(r).SendError(w, http.StatusForbidden, "Access denied")
 			return
 		}
 
 		if !rm.hasRequiredRole(userRole, rm.requiredRole) {
 			rm.logger.Warn("User with role %s attempted to access %s (requires %s)", userRole, r.URL.Path, rm.requiredRole)
-			rm.response.SendError(w, http.StatusForbidden, "Insufficient permissions")
+			rm.response.WithRequest(r).SendError(w, http.StatusForbidden, "Insufficient permissions")
 			return
 		}
 
@@ -131,22 +142,26 @@ func (am *AuthMiddleware) extractToken(r *http.Request) string {
 	return ""
 }
 
+// tokenRole returns the role granted to a token: "admin" for the configured
+// admin token, "user" for anything else.
+func tokenRole(cfg *config.Config, token string) string {
+	adminToken := cfg.Auth.AdminToken
+	if adminToken != "" && subtle.ConstantTimeCompare([]byte(token), []byte(adminToken)) == 1 {
+		return "admin"
+	}
+	return "user"
+}
+
 func (ram *RequireAuthMiddleware) extractToken(r *http.Request) string {
 	return (&AuthMiddleware{}).extractToken(r)
 }
 
 func (rm *RoleMiddleware) hasRequiredRole(userRole, requiredRole string) bool {
-	// Simple role hierarchy: admin > user > viewer.
-	roleHierarchy := map[string]int{
-		"viewer": 1,
-		"user":   2,
-		"admin":  3,
-	}
-
-	userLevel, userExists := roleHierarchy[userRole]
-	requiredLevel, requiredExists := roleHierarchy[requiredRole]
+	// Roles rank by their position in auth.roles; unknown roles rank 0.
+	userLevel := models.RoleLevel(userRole)
+	requiredLevel := models.RoleLevel(requiredRole)
 
-	if !userExists || !requiredExists {
+	if userLevel == 0 || requiredLevel == 0 {
 		return false
 	}
 
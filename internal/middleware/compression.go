@@ -0,0 +1,136 @@
+package middleware
+
+import (
+	"compress/gzip"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"merge-queue/internal/config"
+)
+
+// CompressionMiddleware gzips responses for clients that accept it.
+type CompressionMiddleware struct {
+	config *config.Config
+}
+
+// NewCompressionMiddleware creates a new compression middleware instance.
+func NewCompressionMiddleware(cfg *config.Config) *CompressionMiddleware {
+	return &CompressionMiddleware{config: cfg}
+}
+
+// Handler returns the compression middleware handler.
+func (cm *CompressionMiddleware) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !cm.config.Features.EnableCompression || !strings.Contains(r.Header.Get("Accept-Encoding"), "gzip") {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		w.Header().Add("Vary", "Accept-Encoding")
+
+		gw := &gzipResponseWriter{
+			ResponseWriter: w,
+			statusCode:     http.StatusOK,
+			minSize:        cm.config.Features.MinCompressBytes,
+		}
+
+		// A panicking handler leaves the response to the recovery
+		// middleware, so nothing is flushed on its behalf.
+		completed := false
+		defer func() {
+			if completed {
+				gw.Close()
+			}
+		}()
+
+		next.ServeHTTP(gw, r)
+		completed = true
+	})
+}
+
+// gzipResponseWriter holds the status code until the first Write, then
+// gzips the response if that write, or the declared Content-Length, is at
+// least minSize bytes. Smaller responses are written uncompressed.
+type gzipResponseWriter struct {
+	http.ResponseWriter
+	statusCode  int
+	minSize     int
+	wroteHeader bool
+	gz          *gzip.Writer
+}
+
+// WriteHeader records the status code until the encoding is decided.
+func (gw *gzipResponseWriter) WriteHeader(code int) {
+	if !gw.wroteHeader {
+		gw.statusCode = code
+	}
+}
+
+// Write decides the encoding on the first call, sending the held status,
+// and then writes through the chosen encoding.
+func (gw *gzipResponseWriter) Write(b []byte) (int, error) {
+	if !gw.wroteHeader {
+		gw.writeHeader(len(b))
+	}
+	if gw.gz != nil {
+		return gw.gz.Write(b)
+	}
+	return gw.ResponseWriter.Write(b)
+}
+
+// Flush sends data written so far to the client. Nothing is sent before the
+// first Write.
+func (gw *gzipResponseWriter) Flush() {
+	if !gw.wroteHeader {
+		return
+	}
+	if gw.gz != nil {
+		gw.gz.Flush()
+	}
+	if flusher, ok := gw.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Unwrap exposes the underlying writer to http.ResponseController.
+func (gw *gzipResponseWriter) Unwrap() http.ResponseWriter {
+	return gw.ResponseWriter
+}
+
+// Close sends the held status of a response without a body, or finishes
+// the gzip stream.
+func (gw *gzipResponseWriter) Close() error {
+	if !gw.wroteHeader {
+		gw.wroteHeader = true
+		gw.ResponseWriter.WriteHeader(gw.statusCode)
+		return nil
+	}
+	if gw.gz != nil {
+		return gw.gz.Close()
+	}
+	return nil
+}
+
+// writeHeader chooses the encoding for a response whose first write is
+// size bytes and sends the held status.
+func (gw *gzipResponseWriter) writeHeader(size int) {
+	gw.wroteHeader = true
+
+	if length, err := strconv.Atoi(gw.Header().Get("Content-Length")); err == nil {
+		size = length
+	}
+	if size >= gw.minSize && gw.Header().Get("Content-Encoding") == "" && bodyAllowed(gw.statusCode) {
+		gw.Header().Set("Content-Encoding", "gzip")
+		gw.Header().Del("Content-Length")
+		gw.gz = gzip.NewWriter(gw.ResponseWriter)
+	}
+
+	gw.ResponseWriter.WriteHeader(gw.statusCode)
+}
+
+// bodyAllowed reports whether a response with the given status may carry
+// a body.
+func bodyAllowed(status int) bool {
+	return status >= 200 && status != http.StatusNoContent && status != http.StatusNotModified
+}
@@ -0,0 +1,90 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+	"sync/atomic"
+
+	"merge-queue/internal/config"
+	"merge-queue/pkg/utils"
+)
+
+// ConcurrencyMiddleware caps the number of requests handled at once.
+// Health probes bypass the limit so orchestrators can still reach the
+// server while it is saturated.
+type ConcurrencyMiddleware struct {
+	config   *config.Config
+	logger   *utils.Logger
+	response *utils.ResponseHelper
+	slots    chan struct{}
+	inFlight int64
+}
+
+// NewConcurrencyMiddleware creates a middleware allowing at most
+// features.max_in_flight concurrent requests; 0 disables the limit.
+func NewConcurrencyMiddleware(cfg *config.Config, logger *utils.Logger) *ConcurrencyMiddleware {
+	cm := &ConcurrencyMiddleware{
+		config:   cfg,
+		logger:   logger,
+		response: utils.NewResponseHelperWithLogger(logger),
+	}
+	if cfg.Features.MaxInFlight > 0 {
+		cm.slots = make(chan struct{}, cfg.Features.MaxInFlight)
+	}
+	return cm
+}
+
+// Handler returns the concurrency limiting middleware handler.
+func (cm *ConcurrencyMiddleware) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if cm.slots == nil || cm.isHealthCheck(r.URL.Path) {
+			cm.serve(next, w, r)
+			return
+		}
+
+		select {
+		case cm.slots <- struct{}{}:
+		default:
+			utils.LoggerFromContext(r.Context(), cm.logger).Warn("Concurrency limit of %d reached", cap(cm.slots))
+			w.Header().Set("Retry-After", "1")
+			cm.response.WithRequest(r).SendErrorWithData(w, http.StatusServiceUnavailable, "Server is busy, try again shortly", map[string]interface{}{
+				"retry_after_seconds": 1,
+				"max_in_flight":       cap(cm.slots),
+			})
+			return
+		}
+		// Release in a deferred call so a panicking handler cannot leak a slot.
+		defer func() { <-cm.slots }()
+
+		cm.serve(next, w, r)
+	})
+}
+
+// InFlight returns the number of requests currently being handled.
+func (cm *ConcurrencyMiddleware) InFlight() int64 {
+	return atomic.LoadInt64(&cm.inFlight)
+}
+
+// Helper methods.
+
+func (cm *ConcurrencyMiddleware) serve(next http.Handler, w http.ResponseWriter, r *http.Request) {
+	atomic.AddInt64(&cm.inFlight, 1)
+	defer atomic.AddInt64(&cm.inFlight, -1)
+
+	next.ServeHTTP(w, r)
+}
+
+// isHealthCheck reports whether path is one of the health endpoints.
+func (cm *ConcurrencyMiddleware) isHealthCheck(path string) bool {
+	prefix := cm.config.Server.BasePath + "/api/v1/"
+	if !strings.HasPrefix(path, prefix) {
+		return false
+	}
+
+	switch strings.TrimPrefix(path, prefix) {
+	case "health", "ready", "live":
+		return true
+	default:
+		return false
+	}
+}
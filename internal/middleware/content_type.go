@@ -0,0 +1,71 @@
+package middleware
+
+import (
+	"mime"
+	"net/http"
+
+	"merge-queue/internal/config"
+	"merge-queue/pkg/utils"
+)
+
+// ContentTypeMiddleware rejects POST, PUT and PATCH bodies that are not
+// declared as JSON when features.require_json_content_type is on.
+type ContentTypeMiddleware struct {
+	config   *config.Config
+	response *utils.ResponseHelper
+}
+
+// NewContentTypeMiddleware creates a new content type middleware instance.
+func NewContentTypeMiddleware(cfg *config.Config, logger *utils.Logger) *ContentTypeMiddleware {
+	return &ContentTypeMiddleware{
+		config:   cfg,
+		response: utils.NewResponseHelperWithLogger(logger),
+	}
+}
+
+// Handler returns the content type middleware handler.
+func (ctm *ContentTypeMiddleware) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !ctm.config.Features.RequireJSONContentType || !hasBody(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		switch r.Method {
+		case http.MethodPost, http.MethodPut, http.MethodPatch:
+		default:
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		if err != nil || !isJSONMediaType(mediaType, r.Method) {
+			ctm.response.WithRequest(r).SendErrorf(w, http.StatusUnsupportedMediaType,
+				"Unsupported Content-Type %q: expected application/json", r.Header.Get("Content-Type"))
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Helper methods.
+
+// hasBody reports whether the request may carry a body. Requests with an
+// unknown length (chunked) count as having one.
+func hasBody(r *http.Request) bool {
+	return r.Body != nil && r.Body != http.NoBody && r.ContentLength != 0
+}
+
+// isJSONMediaType reports whether mediaType is accepted for method. JSON
+// Patch documents are only meaningful on PATCH.
+func isJSONMediaType(mediaType, method string) bool {
+	switch mediaType {
+	case "application/json":
+		return true
+	case "application/json-patch+json":
+		return method == http.MethodPatch
+	default:
+		return false
+	}
+}
@@ -1,22 +1,53 @@
 package middleware
 
 import (
+	"bufio"
 	"fmt"
 	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
 
 	"merge-queue/internal/config"
+	"merge-queue/pkg/utils"
 )
 
-// CORSMiddleware handles Cross-Origin Resource Sharing.
+// CORSMiddleware handles Cross-Origin Resource Sharing for the origins in
+// features.cors_allowed_origins. A "*" entry allows every origin with a
+// wildcard header; otherwise only a matching request origin is echoed back.
 type CORSMiddleware struct {
-	config *config.Config
+	config         *config.Config
+	anyOrigin      bool
+	originMatchers []originMatcher
 }
 
 // NewCORSMiddleware creates a new CORS middleware instance.
 func NewCORSMiddleware(cfg *config.Config) *CORSMiddleware {
-	return &CORSMiddleware{config: cfg}
+	cm := &CORSMiddleware{config: cfg}
+
+	for _, origin := range cfg.Features.CORSAllowedOrigins {
+		matcher := newOriginMatcher(origin)
+		if matcher.any {
+			cm.anyOrigin = true
+		}
+		cm.originMatchers = append(cm.originMatchers, matcher)
+	}
+
+	return cm
 }
 
+// exposedHeaders lists the response headers cross-origin scripts may read.
+const exposedHeaders = "X-Total-Count, X-Next-Cursor, X-Request-ID"
+
+// corsMethods and corsHeaders are the methods and request headers allowed
+// cross-origin.
+var (
+	corsMethods = []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"}
+	corsHeaders = []string{"Content-Type", "Authorization", "X-Requested-With"}
+)
+
 // Handler returns the CORS middleware handler.
 func (cm *CORSMiddleware) Handler(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -25,15 +56,26 @@ func (cm *CORSMiddleware) Handler(next http.Handler) http.Handler {
 			return
 		}
 
-		// Set CORS headers.
-		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Requested-With")
-		w.Header().Set("Access-Control-Max-Age", "86400") // 24 hours.
+		// Set CORS headers, reflecting the request origin when it is on the
+		// allow list.
+		if cm.anyOrigin {
+			w.Header().Set("Access-Control-Allow-Origin", "*")
+		} else {
+			w.Header().Add("Vary", "Origin")
+			if origin := r.Header.Get("Origin"); origin != "" && cm.isAllowed(origin) {
+				w.Header().Set("Access-Control-Allow-Origin", origin)
+			}
+		}
+		w.Header().Set("Access-Control-Allow-Methods", strings.Join(corsMethods, ", "))
+		w.Header().Set("Access-Control-Allow-Headers", strings.Join(corsHeaders, ", "))
+		w.Header().Set("Access-Control-Expose-Headers", exposedHeaders)
+		if cm.config.Features.CORSMaxAge > 0 {
+			w.Header().Set("Access-Control-Max-Age", fmt.Sprintf("%d", cm.config.Features.CORSMaxAge))
+		}
 
 		// Handle preflight requests.
 		if r.Method == "OPTIONS" {
-			w.WriteHeader(http.StatusOK)
+			w.WriteHeader(http.StatusNoContent)
 			return
 		}
 
@@ -41,22 +83,196 @@ func (cm *CORSMiddleware) Handler(next http.Handler) http.Handler {
 	})
 }
 
+// isAllowed reports whether any allowed origin matches origin.
+func (cm *CORSMiddleware) isAllowed(origin string) bool {
+	for _, matcher := range cm.originMatchers {
+		if matcher.matches(origin) {
+			return true
+		}
+	}
+	return false
+}
+
 // ConfigurableCORSMiddleware allows more fine-grained CORS control.
+// AllowedOrigins is fixed at construction; use SetAllowedOrigins to change
+// the origins while serving.
 type ConfigurableCORSMiddleware struct {
 	AllowedOrigins []string
 	AllowedMethods []string
 	AllowedHeaders []string
 	MaxAge         int
+
+	mutex          sync.RWMutex
+	originMatchers []originMatcher
+
+	// Set by NewFileCORSMiddleware.
+	originsFile string
+	logger      *utils.Logger
+	stop        chan struct{}
+	once        sync.Once
+}
+
+// originMatcher matches a request origin against one allowed origin entry.
+// Entries may be "*", an exact origin, or a wildcard subdomain pattern
+// such as "https://*.example.com".
+type originMatcher struct {
+	any    bool
+	exact  string
+	prefix string
+	suffix string
+}
+
+// newOriginMatcher compiles an allowed origin entry into a matcher.
+func newOriginMatcher(pattern string) originMatcher {
+	pattern = strings.TrimSpace(pattern)
+	if pattern == "*" {
+		return originMatcher{any: true}
+	}
+
+	if idx := strings.Index(pattern, "*."); idx >= 0 {
+		return originMatcher{
+			prefix: pattern[:idx],
+			suffix: pattern[idx+1:],
+		}
+	}
+
+	return originMatcher{exact: pattern}
+}
+
+// matches reports whether the origin is allowed by this matcher.
+func (om originMatcher) matches(origin string) bool {
+	if om.any {
+		return true
+	}
+
+	if om.exact != "" {
+		return om.exact == origin
+	}
+
+	if !strings.HasPrefix(origin, om.prefix) || !strings.HasSuffix(origin, om.suffix) {
+		return false
+	}
+
+	// The wildcard must cover at least one subdomain label and cannot
+	// swallow a path or port.
+	host := origin[len(om.prefix) : len(origin)-len(om.suffix)]
+	return host != "" && !strings.ContainsAny(host, "/:")
 }
 
 // NewConfigurableCORSMiddleware creates a configurable CORS middleware.
 func NewConfigurableCORSMiddleware(origins, methods, headers []string, maxAge int) *ConfigurableCORSMiddleware {
-	return &ConfigurableCORSMiddleware{
+	ccm := &ConfigurableCORSMiddleware{
 		AllowedOrigins: origins,
 		AllowedMethods: methods,
 		AllowedHeaders: headers,
 		MaxAge:         maxAge,
 	}
+	ccm.SetAllowedOrigins(origins)
+
+	return ccm
+}
+
+// NewFileCORSMiddleware creates a configurable CORS middleware that allows
+// the origins listed in features.cors_origins_file, with the same methods,
+// headers and max age as CORSMiddleware. It fails if the file cannot be
+// read; call Watch to re-read it on SIGHUP.
+func NewFileCORSMiddleware(cfg *config.Config, logger *utils.Logger) (*ConfigurableCORSMiddleware, error) {
+	origins, err := ReadOriginsFile(cfg.Features.CORSOriginsFile)
+	if err != nil {
+		return nil, err
+	}
+
+	ccm := NewConfigurableCORSMiddleware(origins, corsMethods, corsHeaders, cfg.Features.CORSMaxAge)
+	ccm.originsFile = cfg.Features.CORSOriginsFile
+	ccm.logger = logger
+	ccm.stop = make(chan struct{})
+
+	logger.With("component", "cors").Info("Loaded %d allowed origins from %s", len(origins), ccm.originsFile)
+
+	return ccm, nil
+}
+
+// ReadOriginsFile reads allowed origins from path, one per line. Blank
+// lines and lines starting with # are skipped.
+func ReadOriginsFile(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open CORS origins file: %w", err)
+	}
+	defer file.Close()
+
+	var origins []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		origins = append(origins, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read CORS origins file: %w", err)
+	}
+
+	return origins, nil
+}
+
+// SetAllowedOrigins compiles origins and replaces the allowed list. It is
+// safe to call while requests are being served.
+func (ccm *ConfigurableCORSMiddleware) SetAllowedOrigins(origins []string) {
+	var matchers []originMatcher
+	for _, origin := range origins {
+		if strings.TrimSpace(origin) == "" {
+			continue
+		}
+		matchers = append(matchers, newOriginMatcher(origin))
+	}
+
+	ccm.mutex.Lock()
+	ccm.originMatchers = matchers
+	ccm.mutex.Unlock()
+}
+
+// ReloadOrigins re-reads the origins file. On failure the current origins
+// stay in use.
+func (ccm *ConfigurableCORSMiddleware) ReloadOrigins() error {
+	origins, err := ReadOriginsFile(ccm.originsFile)
+	if err != nil {
+		return err
+	}
+
+	ccm.SetAllowedOrigins(origins)
+	ccm.logger.With("component", "cors").Info("Reloaded %d allowed origins from %s", len(origins), ccm.originsFile)
+
+	return nil
+}
+
+// Watch reloads the origins file on every SIGHUP until Stop is called.
+func (ccm *ConfigurableCORSMiddleware) Watch() {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	for {
+		select {
+		case <-hup:
+		case <-ccm.stop:
+			return
+		}
+
+		if err := ccm.ReloadOrigins(); err != nil {
+			ccm.logger.Error("Keeping current CORS origins: %v", err)
+		}
+	}
+}
+
+// Stop ends Watch. It does nothing for middleware not created by
+// NewFileCORSMiddleware.
+func (ccm *ConfigurableCORSMiddleware) Stop() {
+	if ccm.stop == nil {
+		return
+	}
+	ccm.once.Do(func() { close(ccm.stop) })
 }
 
 // Handler returns the configurable CORS middleware handler.
@@ -65,14 +281,14 @@ func (ccm *ConfigurableCORSMiddleware) Handler(next http.Handler) http.Handler {
 		origin := r.Header.Get("Origin")
 
 		// Check if origin is allowed.
-		allowed := false
-		for _, allowedOrigin := range ccm.AllowedOrigins {
-			if allowedOrigin == "*" || allowedOrigin == origin {
-				allowed = true
-				break
-			}
-		}
+		allowed := origin != "" && ccm.isAllowed(origin)
 
+		// Reflect the exact request origin when it matches. The response
+		// depends on the origin whether or not it matched, so caches must
+		// key on it either way.
+		if origin != "" {
+			w.Header().Add("Vary", "Origin")
+		}
 		if allowed {
 			w.Header().Set("Access-Control-Allow-Origin", origin)
 		}
@@ -100,16 +316,33 @@ func (ccm *ConfigurableCORSMiddleware) Handler(next http.Handler) http.Handler {
 			w.Header().Set("Access-Control-Allow-Headers", headers)
 		}
 
+		w.Header().Set("Access-Control-Expose-Headers", exposedHeaders)
+
 		if ccm.MaxAge > 0 {
 			w.Header().Set("Access-Control-Max-Age", fmt.Sprintf("%d", ccm.MaxAge))
 		}
 
 		// Handle preflight requests.
 		if r.Method == "OPTIONS" {
-			w.WriteHeader(http.StatusOK)
+			w.WriteHeader(http.StatusNoContent)
 			return
 		}
 
 		next.ServeHTTP(w, r)
 	})
 }
+
+// Helper methods.
+
+// isAllowed reports whether any allowed origin matches origin.
+func (ccm *ConfigurableCORSMiddleware) isAllowed(origin string) bool {
+	ccm.mutex.RLock()
+	defer ccm.mutex.RUnlock()
+
+	for _, matcher := range ccm.originMatchers {
+		if matcher.matches(origin) {
+			return true
+		}
+	}
+	return false
+}
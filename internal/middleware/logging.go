@@ -1,17 +1,40 @@
 package middleware
 
 import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"io"
 	"net/http"
+	"net/url"
+	"strings"
+	"sync"
 	"time"
 
 	"merge-queue/internal/config"
 	"merge-queue/pkg/utils"
 )
 
-// LoggingMiddleware logs HTTP requests.
+// LoggingMiddleware logs HTTP requests, either through the application
+// logger or, once SetAccessLog is called, as JSON lines to a separate sink.
 type LoggingMiddleware struct {
 	config *config.Config
 	logger *utils.Logger
+
+	accessMutex sync.Mutex
+	accessLog   *json.Encoder
+}
+
+// accessLogEntry is one JSON line in the access log.
+type accessLogEntry struct {
+	Time       time.Time `json:"time"`
+	RequestID  string    `json:"request_id"`
+	Method     string    `json:"method"`
+	Path       string    `json:"path"`
+	Status     int       `json:"status"`
+	DurationMs float64   `json:"duration_ms"`
+	RemoteAddr string    `json:"remote_addr"`
+	UserAgent  string    `json:"user_agent,omitempty"`
 }
 
 // NewLoggingMiddleware creates a new logging middleware instance.
@@ -22,9 +45,27 @@ func NewLoggingMiddleware(cfg *config.Config, logger *utils.Logger) *LoggingMidd
 	}
 }
 
+// SetAccessLog sends access log entries to w as JSON lines instead of the
+// application logger.
+func (lm *LoggingMiddleware) SetAccessLog(w io.Writer) {
+	lm.accessMutex.Lock()
+	defer lm.accessMutex.Unlock()
+
+	lm.accessLog = json.NewEncoder(w)
+}
+
 // Handler returns the logging middleware handler.
 func (lm *LoggingMiddleware) Handler(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Attach a per-request logger so every log line for this request
+		// carries the same request ID.
+		requestID := r.Header.Get("X-Request-ID")
+		if requestID == "" {
+			requestID = newRequestID()
+		}
+		w.Header().Set("X-Request-ID", requestID)
+		r = r.WithContext(utils.ContextWithLogger(r.Context(), lm.logger.With("request_id", requestID)))
+
 		if !lm.config.Features.EnableLogging {
 			next.ServeHTTP(w, r)
 			return
@@ -39,10 +80,36 @@ func (lm *LoggingMiddleware) Handler(next http.Handler) http.Handler {
 
 		duration := time.Since(start)
 
-		lm.logger.Info(
+		// Probe traffic is only worth seeing when debugging.
+		if lm.isExcluded(r.URL.Path) {
+			utils.LoggerFromContext(r.Context(), lm.logger).Debug(
+				"%s %s %d %v %s",
+				r.Method,
+				redactURL(r.URL, lm.config.Features.RedactedQueryParams),
+				wrapped.statusCode,
+				duration,
+				r.RemoteAddr,
+			)
+			return
+		}
+
+		if lm.writeAccessLog(accessLogEntry{
+			Time:       start.UTC(),
+			RequestID:  requestID,
+			Method:     r.Method,
+			Path:       redactURL(r.URL, lm.config.Features.RedactedQueryParams),
+			Status:     wrapped.statusCode,
+			DurationMs: float64(duration.Microseconds()) / 1000,
+			RemoteAddr: r.RemoteAddr,
+			UserAgent:  r.UserAgent(),
+		}) {
+			return
+		}
+
+		utils.LoggerFromContext(r.Context(), lm.logger).Info(
 			"%s %s %d %v %s",
 			r.Method,
-			r.URL.Path,
+			redactURL(r.URL, lm.config.Features.RedactedQueryParams),
 			wrapped.statusCode,
 			duration,
 			r.RemoteAddr,
@@ -50,6 +117,67 @@ func (lm *LoggingMiddleware) Handler(next http.Handler) http.Handler {
 	})
 }
 
+// isExcluded reports whether path, once the base path is removed, starts
+// with one of the configured log exclusion prefixes.
+func (lm *LoggingMiddleware) isExcluded(path string) bool {
+	path = strings.TrimPrefix(path, lm.config.Server.BasePath)
+	for _, prefix := range lm.config.Features.LogExcludedPaths {
+		if prefix != "" && strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// writeAccessLog writes entry to the access log sink, reporting false if
+// none is configured.
+func (lm *LoggingMiddleware) writeAccessLog(entry accessLogEntry) bool {
+	lm.accessMutex.Lock()
+	defer lm.accessMutex.Unlock()
+
+	if lm.accessLog == nil {
+		return false
+	}
+	if err := lm.accessLog.Encode(entry); err != nil {
+		lm.logger.Error("Failed to write access log: %v", err)
+	}
+	return true
+}
+
+// redactURL returns the request path and query with the values of the
+// given query parameters replaced by "***".
+func redactURL(u *url.URL, params []string) string {
+	if u.RawQuery == "" {
+		return u.Path
+	}
+
+	pairs := strings.Split(u.RawQuery, "&")
+	for i, pair := range pairs {
+		key, _, _ := strings.Cut(pair, "=")
+		if name, err := url.QueryUnescape(key); err == nil {
+			key = name
+		}
+
+		for _, param := range params {
+			if strings.EqualFold(key, param) {
+				pairs[i] = key + "=***"
+				break
+			}
+		}
+	}
+
+	return u.Path + "?" + strings.Join(pairs, "&")
+}
+
+// newRequestID returns a random hex identifier for a request.
+func newRequestID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "unknown"
+	}
+	return hex.EncodeToString(b)
+}
+
 // responseWriter wraps http.ResponseWriter to capture status code.
 type responseWriter struct {
 	http.ResponseWriter
@@ -62,14 +190,30 @@ func (rw *responseWriter) WriteHeader(code int) {
 	rw.ResponseWriter.WriteHeader(code)
 }
 
+// Unwrap exposes the underlying writer to http.ResponseController.
+func (rw *responseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
+// Flush passes flushes through so streamed responses are not held back.
+func (rw *responseWriter) Flush() {
+	if flusher, ok := rw.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
 // DetailedLoggingMiddleware provides more detailed request logging.
 type DetailedLoggingMiddleware struct {
+	config *config.Config
 	logger *utils.Logger
 }
 
 // NewDetailedLoggingMiddleware creates a detailed logging middleware.
-func NewDetailedLoggingMiddleware(logger *utils.Logger) *DetailedLoggingMiddleware {
-	return &DetailedLoggingMiddleware{logger: logger}
+func NewDetailedLoggingMiddleware(cfg *config.Config, logger *utils.Logger) *DetailedLoggingMiddleware {
+	return &DetailedLoggingMiddleware{
+		config: cfg,
+		logger: logger,
+	}
 }
 
 // Handler returns the detailed logging middleware handler.
@@ -81,7 +225,7 @@ func (dlm *DetailedLoggingMiddleware) Handler(next http.Handler) http.Handler {
 		dlm.logger.Debug(
 			"Request started: %s %s from %s, User-Agent: %s",
 			r.Method,
-			r.URL.String(),
+			redactURL(r.URL, dlm.config.Features.RedactedQueryParams),
 			r.RemoteAddr,
 			r.Header.Get("User-Agent"),
 		)
@@ -0,0 +1,90 @@
+package middleware
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/gorilla/mux"
+
+	"merge-queue/pkg/utils"
+)
+
+// MethodsMiddleware restricts a route group to a declared set of HTTP
+// methods. OPTIONS is always let through so CORS preflight keeps working.
+type MethodsMiddleware struct {
+	allowed     map[string]bool
+	allowHeader string
+	response    *utils.ResponseHelper
+}
+
+// NewMethodsMiddleware creates a middleware allowing only the given methods.
+func NewMethodsMiddleware(methods []string, logger *utils.Logger) *MethodsMiddleware {
+	allowed := make(map[string]bool, len(methods))
+	for _, method := range methods {
+		allowed[strings.ToUpper(strings.TrimSpace(method))] = true
+	}
+	allowed[http.MethodOptions] = true
+
+	return &MethodsMiddleware{
+		allowed:     allowed,
+		allowHeader: joinMethods(allowed),
+		response:    utils.NewResponseHelperWithLogger(logger),
+	}
+}
+
+// Handler returns the methods middleware handler.
+func (mm *MethodsMiddleware) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !mm.allowed[r.Method] {
+			w.Header().Set("Allow", mm.allowHeader)
+			mm.response.WithRequest(r).SendErrorf(w, http.StatusMethodNotAllowed, "Method %s not allowed", r.Method)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// NewMethodNotAllowedHandler returns a handler for requests whose path
+// matches a route but whose method does not. It lists the methods the
+// path does accept in the Allow header. Paths matched only by the OPTIONS
+// catch-all are unknown, so they go to the router's NotFoundHandler.
+func NewMethodNotAllowedHandler(router *mux.Router, logger *utils.Logger) http.Handler {
+	response := utils.NewResponseHelperWithLogger(logger)
+	candidates := []string{
+		http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch,
+		http.MethodDelete, http.MethodHead, http.MethodOptions,
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		allowed := make(map[string]bool)
+		for _, method := range candidates {
+			probe := r.Clone(r.Context())
+			probe.Method = method
+
+			var match mux.RouteMatch
+			if router.Match(probe, &match) && match.MatchErr == nil {
+				allowed[method] = true
+			}
+		}
+
+		if len(allowed) == 1 && allowed[http.MethodOptions] && router.NotFoundHandler != nil {
+			router.NotFoundHandler.ServeHTTP(w, r)
+			return
+		}
+
+		w.Header().Set("Allow", joinMethods(allowed))
+		response.WithRequest(r).SendErrorf(w, http.StatusMethodNotAllowed, "Method %s not allowed for %s", r.Method, r.URL.Path)
+	})
+}
+
+// joinMethods renders a method set as a sorted Allow header value.
+func joinMethods(methods map[string]bool) string {
+	list := make([]string, 0, len(methods))
+	for method := range methods {
+		list = append(list, method)
+	}
+	sort.Strings(list)
+	return strings.Join(list, ", ")
+}
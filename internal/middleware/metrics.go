@@ -0,0 +1,124 @@
+package middleware
+
+import (
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"merge-queue/internal/config"
+)
+
+// maxLatencySamples bounds the memory used by one latency window.
+const maxLatencySamples = 10000
+
+// MetricsMiddleware records request latencies over a rolling window.
+type MetricsMiddleware struct {
+	config      *config.Config
+	samples     []time.Duration
+	next        int
+	windowStart time.Time
+	mutex       sync.Mutex
+}
+
+// LatencySnapshot summarizes the latencies recorded in the current window.
+type LatencySnapshot struct {
+	Enabled     bool      `json:"enabled"`
+	Count       int       `json:"count"`
+	P50         string    `json:"p50"`
+	P95         string    `json:"p95"`
+	P99         string    `json:"p99"`
+	Max         string    `json:"max"`
+	WindowStart time.Time `json:"window_start"`
+	Window      string    `json:"window"`
+	InFlight    int64     `json:"in_flight"`
+}
+
+// NewMetricsMiddleware creates a new metrics middleware instance.
+func NewMetricsMiddleware(cfg *config.Config) *MetricsMiddleware {
+	return &MetricsMiddleware{
+		config:      cfg,
+		windowStart: time.Now().UTC(),
+	}
+}
+
+// Handler returns the metrics middleware handler.
+func (mm *MetricsMiddleware) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !mm.config.Features.EnableMetrics {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		start := time.Now()
+		next.ServeHTTP(w, r)
+		mm.record(time.Since(start))
+	})
+}
+
+// Latency returns percentiles for the current window.
+func (mm *MetricsMiddleware) Latency() LatencySnapshot {
+	mm.mutex.Lock()
+	mm.rollWindow(time.Now())
+	sorted := append([]time.Duration(nil), mm.samples...)
+	windowStart := mm.windowStart
+	mm.mutex.Unlock()
+
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	snapshot := LatencySnapshot{
+		Enabled:     mm.config.Features.EnableMetrics,
+		Count:       len(sorted),
+		P50:         percentile(sorted, 50).String(),
+		P95:         percentile(sorted, 95).String(),
+		P99:         percentile(sorted, 99).String(),
+		Max:         percentile(sorted, 100).String(),
+		WindowStart: windowStart,
+		Window:      mm.config.Features.LatencyWindow.String(),
+	}
+
+	return snapshot
+}
+
+// Helper methods.
+
+func (mm *MetricsMiddleware) record(d time.Duration) {
+	mm.mutex.Lock()
+	defer mm.mutex.Unlock()
+
+	mm.rollWindow(time.Now())
+
+	// Once full, overwrite the oldest sample.
+	if len(mm.samples) < maxLatencySamples {
+		mm.samples = append(mm.samples, d)
+		return
+	}
+	mm.samples[mm.next] = d
+	mm.next = (mm.next + 1) % maxLatencySamples
+}
+
+// rollWindow discards all samples once the window has elapsed. Callers
+// must hold the mutex.
+func (mm *MetricsMiddleware) rollWindow(now time.Time) {
+	window := time.Duration(mm.config.Features.LatencyWindow)
+	if window <= 0 || now.Sub(mm.windowStart) < window {
+		return
+	}
+
+	mm.samples = mm.samples[:0]
+	mm.next = 0
+	mm.windowStart = now.UTC()
+}
+
+// percentile returns the nearest-rank percentile of sorted samples.
+func percentile(sorted []time.Duration, p int) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+
+	rank := (p*len(sorted) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
@@ -2,7 +2,9 @@ package middleware
 
 import (
 	"fmt"
+	"math"
 	"net/http"
+	"sort"
 	"sync"
 	"time"
 
@@ -10,7 +12,9 @@ import (
 	"merge-queue/pkg/utils"
 )
 
-// RateLimitMiddleware implements basic rate limiting.
+// RateLimitMiddleware implements per-client token-bucket rate limiting.
+// Buckets hold up to the configured burst and refill at the per-minute
+// rate.
 type RateLimitMiddleware struct {
 	config        *config.Config
 	logger        *utils.Logger
@@ -20,10 +24,29 @@ type RateLimitMiddleware struct {
 	cleanupTicker *time.Ticker
 }
 
-// clientInfo tracks request information for a client.
+// requestWindow is how far back ClientState.RequestsLastMinute counts,
+// matching the per-minute rate limit.
+const requestWindow = 60
+
+// clientInfo tracks a client's token bucket.
 type clientInfo struct {
-	requests []time.Time
-	lastSeen time.Time
+	tokens        float64
+	lastRefill    time.Time
+	lastSeen      time.Time
+	perSecond     [requestWindow]int // Requests per second over the last minute, indexed by Unix second.
+	currentSecond int64              // Unix second of the latest request counted in perSecond.
+}
+
+// ClientState describes one tracked client for debugging.
+// RequestsLastMinute counts requests in the past minute, allowed or not,
+// to compare with features.rate_limit_per_min, and TokensRemaining is the
+// bucket level after the last request. Idle clients are forgotten after
+// ten minutes.
+type ClientState struct {
+	Key                string    `json:"key"`
+	RequestsLastMinute int       `json:"requests_last_minute"`
+	TokensRemaining    int       `json:"tokens_remaining"`
+	LastSeen           time.Time `json:"last_seen"`
 }
 
 // NewRateLimitMiddleware creates a new rate limiting middleware.
@@ -31,7 +54,7 @@ func NewRateLimitMiddleware(cfg *config.Config, logger *utils.Logger) *RateLimit
 	rlm := &RateLimitMiddleware{
 		config:   cfg,
 		logger:   logger,
-		response: utils.NewResponseHelper(),
+		response: utils.NewResponseHelperWithLogger(logger),
 		clients:  make(map[string]*clientInfo),
 	}
 
@@ -52,22 +75,25 @@ func (rlm *RateLimitMiddleware) Handler(next http.Handler) http.Handler {
 
 		clientIP := rlm.getClientIP(r)
 
-		if rlm.isRateLimited(clientIP) {
+		allowed, remaining, wait := rlm.take(clientIP)
+		rlm.setRateLimitHeaders(w, remaining, wait)
+
+		if !allowed {
 			rlm.logger.Warn("Rate limit exceeded for client %s", clientIP)
-			w.Header().Set("X-RateLimit-Limit", fmt.Sprintf("%d", rlm.config.Features.RateLimitPerMin))
-			w.Header().Set("X-RateLimit-Remaining", "0")
-			w.Header().Set("Retry-After", "60")
-			rlm.response.SendError(w, http.StatusTooManyRequests, "Rate limit exceeded")
+			// Retry once the bucket has refilled a token.
+			retryAfter := wait
+			if retryAfter < 1 {
+				retryAfter = 1
+			}
+			w.Header().Set("Retry-After", fmt.Sprintf("%d", retryAfter))
+			rlm.response.WithRequest(r).SendErrorWithData(w, http.StatusTooManyRequests, "Rate limit exceeded", map[string]interface{}{
+				"retry_after_seconds": retryAfter,
+				"limit":               rlm.config.Features.RateLimitPerMin,
+				"burst":               rlm.burst(),
+			})
 			return
 		}
 
-		rlm.recordRequest(clientIP)
-
-		// Add rate limit headers.
-		remaining := rlm.getRemainingRequests(clientIP)
-		w.Header().Set("X-RateLimit-Limit", fmt.Sprintf("%d", rlm.config.Features.RateLimitPerMin))
-		w.Header().Set("X-RateLimit-Remaining", fmt.Sprintf("%d", remaining))
-
 		next.ServeHTTP(w, r)
 	})
 }
@@ -79,6 +105,49 @@ func (rlm *RateLimitMiddleware) Stop() {
 	}
 }
 
+// Reset forgets every tracked client, restoring everyone's full quota.
+// It returns the number of clients that were cleared.
+func (rlm *RateLimitMiddleware) Reset() int {
+	rlm.mutex.Lock()
+	defer rlm.mutex.Unlock()
+
+	cleared := len(rlm.clients)
+	rlm.clients = make(map[string]*clientInfo)
+
+	return cleared
+}
+
+// Clients returns up to limit tracked clients, most recently seen first,
+// along with the total number tracked.
+func (rlm *RateLimitMiddleware) Clients(limit int) ([]ClientState, int) {
+	now := time.Now().Unix()
+
+	rlm.mutex.RLock()
+	clients := make([]ClientState, 0, len(rlm.clients))
+	for key, client := range rlm.clients {
+		clients = append(clients, ClientState{
+			Key:                key,
+			RequestsLastMinute: client.recentRequests(now),
+			TokensRemaining:    int(client.tokens),
+			LastSeen:           client.lastSeen,
+		})
+	}
+	rlm.mutex.RUnlock()
+
+	sort.Slice(clients, func(i, j int) bool {
+		if !clients[i].LastSeen.Equal(clients[j].LastSeen) {
+			return clients[i].LastSeen.After(clients[j].LastSeen)
+		}
+		return clients[i].Key < clients[j].Key
+	})
+
+	total := len(clients)
+	if limit >= 0 && total > limit {
+		clients = clients[:limit]
+	}
+	return clients, total
+}
+
 // Helper methods.
 
 func (rlm *RateLimitMiddleware) getClientIP(r *http.Request) string {
@@ -96,85 +165,96 @@ func (rlm *RateLimitMiddleware) getClientIP(r *http.Request) string {
 	return r.RemoteAddr
 }
 
-func (rlm *RateLimitMiddleware) isRateLimited(clientIP string) bool {
-	rlm.mutex.RLock()
-	defer rlm.mutex.RUnlock()
-
-	client, exists := rlm.clients[clientIP]
-	if !exists {
-		return false
-	}
-
-	// Count requests in the last minute.
-	now := time.Now()
-	cutoff := now.Add(-time.Minute)
-
-	count := 0
-	for _, reqTime := range client.requests {
-		if reqTime.After(cutoff) {
-			count++
-		}
+// burst returns the bucket capacity: features.rate_limit_burst, or the
+// per-minute limit when that is unset.
+func (rlm *RateLimitMiddleware) burst() int {
+	if rlm.config.Features.RateLimitBurst > 0 {
+		return rlm.config.Features.RateLimitBurst
 	}
-
-	return count >= rlm.config.Features.RateLimitPerMin
+	return rlm.config.Features.RateLimitPerMin
 }
 
-func (rlm *RateLimitMiddleware) recordRequest(clientIP string) {
+// take refills the client's bucket and spends one token if available. It
+// returns whether the request is allowed, the whole tokens left, and the
+// seconds until the next token is added (0 when the bucket is full).
+func (rlm *RateLimitMiddleware) take(clientIP string) (bool, int, int) {
 	rlm.mutex.Lock()
 	defer rlm.mutex.Unlock()
 
 	now := time.Now()
+	capacity := float64(rlm.burst())
+	perSecond := float64(rlm.config.Features.RateLimitPerMin) / 60
 
 	client, exists := rlm.clients[clientIP]
 	if !exists {
-		client = &clientInfo{
-			requests: make([]time.Time, 0),
-			lastSeen: now,
-		}
+		// New clients start with a full bucket so initial bursts succeed.
+		client = &clientInfo{tokens: capacity, lastRefill: now}
 		rlm.clients[clientIP] = client
 	}
 
-	// Add current request.
-	client.requests = append(client.requests, now)
+	client.tokens = math.Min(capacity, client.tokens+now.Sub(client.lastRefill).Seconds()*perSecond)
+	client.lastRefill = now
 	client.lastSeen = now
+	client.countRequest(now.Unix())
 
-	// Clean up old requests.
-	cutoff := now.Add(-time.Minute)
-	validRequests := make([]time.Time, 0)
-	for _, reqTime := range client.requests {
-		if reqTime.After(cutoff) {
-			validRequests = append(validRequests, reqTime)
-		}
+	allowed := client.tokens >= 1
+	if allowed {
+		client.tokens--
 	}
-	client.requests = validRequests
-}
 
-func (rlm *RateLimitMiddleware) getRemainingRequests(clientIP string) int {
-	rlm.mutex.RLock()
-	defer rlm.mutex.RUnlock()
-
-	client, exists := rlm.clients[clientIP]
-	if !exists {
-		return rlm.config.Features.RateLimitPerMin
+	wait := 0
+	if client.tokens < capacity {
+		// Round up so clients never retry a moment too early.
+		missing := 1 - (client.tokens - math.Floor(client.tokens))
+		wait = int(math.Ceil(missing / perSecond))
 	}
 
-	// Count requests in the last minute.
-	now := time.Now()
-	cutoff := now.Add(-time.Minute)
+	return allowed, int(client.tokens), wait
+}
 
-	count := 0
-	for _, reqTime := range client.requests {
-		if reqTime.After(cutoff) {
-			count++
+// countRequest adds a request at Unix second now to the per-second counts,
+// clearing the seconds that have fallen out of the window since the last
+// one. The caller must hold the write lock.
+func (ci *clientInfo) countRequest(now int64) {
+	if now-ci.currentSecond >= requestWindow {
+		ci.perSecond = [requestWindow]int{}
+	} else {
+		for second := ci.currentSecond + 1; second <= now; second++ {
+			ci.perSecond[second%requestWindow] = 0
 		}
 	}
+	if now > ci.currentSecond {
+		ci.currentSecond = now
+	}
+	ci.perSecond[now%requestWindow]++
+}
+
+// recentRequests returns the requests counted in the window ending at Unix
+// second now. The caller must hold the lock.
+func (ci *clientInfo) recentRequests(now int64) int {
+	last := ci.currentSecond
+	if now < last {
+		last = now
+	}
+	first := now - requestWindow + 1
+	if oldest := ci.currentSecond - requestWindow + 1; oldest > first {
+		first = oldest
+	}
 
-	remaining := rlm.config.Features.RateLimitPerMin - count
-	if remaining < 0 {
-		remaining = 0
+	total := 0
+	for second := first; second <= last; second++ {
+		total += ci.perSecond[second%requestWindow]
 	}
+	return total
+}
 
-	return remaining
+// setRateLimitHeaders writes the X-RateLimit-* headers for a client with
+// the given remaining tokens and seconds until the next token.
+func (rlm *RateLimitMiddleware) setRateLimitHeaders(w http.ResponseWriter, remaining, reset int) {
+	w.Header().Set("X-RateLimit-Limit", fmt.Sprintf("%d", rlm.config.Features.RateLimitPerMin))
+	w.Header().Set("X-RateLimit-Burst", fmt.Sprintf("%d", rlm.burst()))
+	w.Header().Set("X-RateLimit-Remaining", fmt.Sprintf("%d", remaining))
+	w.Header().Set("X-RateLimit-Reset", fmt.Sprintf("%d", reset))
 }
 
 func (rlm *RateLimitMiddleware) cleanupOldClients() {
@@ -0,0 +1,38 @@
+package middleware
+
+import "testing"
+
+func TestClientInfoRecentRequests(t *testing.T) {
+	const start = int64(1_700_000_000)
+	client := &clientInfo{}
+
+	for i := 0; i < 3; i++ {
+		client.countRequest(start)
+	}
+	client.countRequest(start + 30)
+
+	tests := []struct {
+		name string
+		now  int64
+		want int
+	}{
+		{name: "same second", now: start, want: 3},
+		{name: "within the minute", now: start + 30, want: 4},
+		{name: "first second expired", now: start + 60, want: 1},
+		{name: "all expired", now: start + 90, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := client.recentRequests(tt.now); got != tt.want {
+				t.Errorf("recentRequests(start+%d) = %d, want %d", tt.now-start, got, tt.want)
+			}
+		})
+	}
+
+	// A request after a long gap starts the count afresh.
+	client.countRequest(start + 200)
+	if got := client.recentRequests(start + 200); got != 1 {
+		t.Errorf("recentRequests() after a gap = %d, want 1", got)
+	}
+}
@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"runtime/debug"
+
+	"merge-queue/pkg/utils"
+)
+
+// RecoveryMiddleware turns handler panics into 500 responses instead of
+// dropping the connection.
+type RecoveryMiddleware struct {
+	logger   *utils.Logger
+	response *utils.ResponseHelper
+}
+
+// NewRecoveryMiddleware creates a new recovery middleware instance.
+func NewRecoveryMiddleware(logger *utils.Logger) *RecoveryMiddleware {
+	return &RecoveryMiddleware{
+		logger:   logger,
+		response: utils.NewResponseHelperWithLogger(logger),
+	}
+}
+
+// Handler returns the recovery middleware handler.
+func (rm *RecoveryMiddleware) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			if err := recover(); err != nil {
+				if err == http.ErrAbortHandler {
+					panic(err)
+				}
+				utils.LoggerFromContext(r.Context(), rm.logger).Error("Panic serving %s %s: %v\n%s", r.Method, r.URL.Path, err, debug.Stack())
+				rm.response.WithRequest(r).SendInternalError(w, "Internal server error", fmt.Errorf("panic: %v", err))
+			}
+		}()
+
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,88 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"merge-queue/internal/config"
+	"merge-queue/pkg/utils"
+)
+
+// TimeoutMiddleware bounds how long a handler may run. A handler still
+// running after server.handler_timeout is abandoned and the client gets a
+// 503 JSON error instead of a connection cut off by the write timeout.
+// Responses are buffered until the handler returns, so streaming endpoints
+// are exempt.
+type TimeoutMiddleware struct {
+	config *config.Config
+	logger *utils.Logger
+	body   string
+}
+
+// NewTimeoutMiddleware creates a new timeout middleware instance.
+func NewTimeoutMiddleware(cfg *config.Config, logger *utils.Logger) *TimeoutMiddleware {
+	body, _ := json.Marshal(map[string]interface{}{
+		"success": false,
+		"error":   "Request timed out",
+		"code":    "TIMEOUT",
+	})
+
+	return &TimeoutMiddleware{
+		config: cfg,
+		logger: logger,
+		body:   string(body) + "\n",
+	}
+}
+
+// Handler returns the timeout middleware handler.
+func (tm *TimeoutMiddleware) Handler(next http.Handler) http.Handler {
+	timeout := time.Duration(tm.config.Server.HandlerTimeout)
+	if timeout <= 0 {
+		return next
+	}
+
+	limited := http.TimeoutHandler(next, timeout, tm.body)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if tm.isStreaming(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		limited.ServeHTTP(&timeoutResponseWriter{ResponseWriter: w}, r)
+	})
+}
+
+// timeoutResponseWriter labels TimeoutHandler's 503 body as JSON.
+// TimeoutHandler writes that body without a Content-Type, while responses
+// from handlers that finish in time arrive with their own headers.
+type timeoutResponseWriter struct {
+	http.ResponseWriter
+}
+
+// WriteHeader sets the JSON Content-Type on a 503 that has none.
+func (tw *timeoutResponseWriter) WriteHeader(code int) {
+	if code == http.StatusServiceUnavailable && tw.Header().Get("Content-Type") == "" {
+		tw.Header().Set("Content-Type", "application/json")
+	}
+	tw.ResponseWriter.WriteHeader(code)
+}
+
+// Unwrap exposes the underlying writer to http.ResponseController.
+func (tw *timeoutResponseWriter) Unwrap() http.ResponseWriter {
+	return tw.ResponseWriter
+}
+
+// Helper methods.
+
+// isStreaming reports whether the request is served by a streaming or
+// long-held response: CSV task listings and long polls. Only the GET
+// listing streams CSV, so other routes asking for CSV stay bounded.
+func (tm *TimeoutMiddleware) isStreaming(r *http.Request) bool {
+	if r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/tasks") && utils.AcceptsCSV(r) {
+		return true
+	}
+	return strings.HasSuffix(r.URL.Path, "/tasks/poll")
+}
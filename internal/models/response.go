@@ -1,6 +1,9 @@
 package models
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+)
 
 // APIResponse represents a standard API response format.
 type APIResponse struct {
@@ -16,6 +19,27 @@ type ErrorResponse struct {
 	Code    string `json:"code"`
 	Message string `json:"message"`
 	Details string `json:"details,omitempty"`
+	Stack   string `json:"stack,omitempty"` // Goroutine stack for 5xx errors, in debug mode only.
+}
+
+// JSONPatchOperation represents a single RFC 6902 JSON Patch operation.
+type JSONPatchOperation struct {
+	Op    string          `json:"op"` // "add", "remove", "replace", "test"
+	Path  string          `json:"path"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// FieldValidation represents the validation outcome for a single field.
+type FieldValidation struct {
+	Field   string `json:"field"`
+	Valid   bool   `json:"valid"`
+	Message string `json:"message,omitempty"`
+}
+
+// TaskValidationResult represents field-level validation of a task request.
+type TaskValidationResult struct {
+	Valid  bool              `json:"valid"`
+	Fields []FieldValidation `json:"fields"`
 }
 
 // PaginationMeta represents pagination metadata.
@@ -32,24 +56,55 @@ type HealthResponse struct {
 	Version   string    `json:"version"`
 	Timestamp time.Time `json:"timestamp"`
 	Uptime    string    `json:"uptime,omitempty"`
+	TasksUsed int       `json:"tasks_used"`
+	TasksMax  int       `json:"tasks_max"`
+	Banner    string    `json:"banner,omitempty"`
 }
 
 // CreateTaskRequest represents a request to create a task.
 type CreateTaskRequest struct {
-	Title       string   `json:"title" validate:"required,max=200"`
-	Description string   `json:"description" validate:"max=1000"`
-	Status      string   `json:"status" validate:"omitempty,oneof=pending in-progress completed cancelled"`
-	Priority    string   `json:"priority" validate:"omitempty,oneof=low medium high critical"`
-	AssignedTo  string   `json:"assigned_to" validate:"omitempty,max=50"`
-	Tags        []string `json:"tags" validate:"omitempty,dive,max=50"`
+	Title       string            `json:"title" validate:"required,max=200"`
+	Description string            `json:"description" validate:"max=1000"`
+	Status      string            `json:"status" validate:"omitempty,oneof=pending in-progress completed cancelled"`
+	Priority    string            `json:"priority" validate:"omitempty,oneof=low medium high critical"`
+	AssignedTo  string            `json:"assigned_to" validate:"omitempty,max=50"`
+	Tags        []string          `json:"tags" validate:"omitempty,dive,max=50"`
+	DueDate     *time.Time        `json:"due_date"` // Must not be in the past.
+	Metadata    map[string]string `json:"metadata"`
 }
 
 // UpdateTaskRequest represents a request to update a task.
 type UpdateTaskRequest struct {
-	Title       *string  `json:"title,omitempty" validate:"omitempty,max=200"`
-	Description *string  `json:"description,omitempty" validate:"omitempty,max=1000"`
-	Status      *string  `json:"status,omitempty" validate:"omitempty,oneof=pending in-progress completed cancelled"`
-	Priority    *string  `json:"priority,omitempty" validate:"omitempty,oneof=low medium high critical"`
-	AssignedTo  *string  `json:"assigned_to,omitempty" validate:"omitempty,max=50"`
-	Tags        []string `json:"tags,omitempty" validate:"omitempty,dive,max=50"`
+	Title       *string           `json:"title,omitempty" validate:"omitempty,max=200"`
+	Description *string           `json:"description,omitempty" validate:"omitempty,max=1000"`
+	Status      *string           `json:"status,omitempty" validate:"omitempty,oneof=pending in-progress completed cancelled"`
+	Priority    *string           `json:"priority,omitempty" validate:"omitempty,oneof=low medium high critical"`
+	AssignedTo  *string           `json:"assigned_to,omitempty" validate:"omitempty,max=50"`
+	DueDate     *time.Time        `json:"due_date,omitempty"`
+	Tags        []string          `json:"tags,omitempty" validate:"omitempty,dive,max=50"`
+	TagsAdd     []string          `json:"tags_add,omitempty" validate:"omitempty,dive,max=50"`
+	TagsRemove  []string          `json:"tags_remove,omitempty" validate:"omitempty,dive,max=50"`
+	Metadata    map[string]string `json:"metadata,omitempty"` // Replaces all metadata; an empty object clears it.
+}
+
+// BulkPriorityRequest sets the priority of several tasks at once.
+type BulkPriorityRequest struct {
+	TaskIDs  []int  `json:"task_ids"`
+	Priority string `json:"priority"`
+}
+
+// BulkUpdateResult is the outcome of a bulk update for one task.
+type BulkUpdateResult struct {
+	ID      int    `json:"id"`
+	Updated bool   `json:"updated"`
+	Error   string `json:"error,omitempty"`
+}
+
+// TaskView is a task as returned to clients, augmented with fields derived
+// at response time rather than stored. Requested with ?include=derived.
+type TaskView struct {
+	*Task
+	AgeHuman           string   `json:"age_human"`           // Time since creation, e.g. "3 days ago".
+	Overdue            bool     `json:"overdue"`             // Past its due date and still open.
+	AllowedTransitions []string `json:"allowed_transitions"` // Statuses the task may move to next.
 }
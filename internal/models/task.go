@@ -7,34 +7,65 @@ import (
 
 // Task represents a task in our system.
 type Task struct {
-	ID          int       `json:"id"`
-	Title       string    `json:"title"`
-	Description string    `json:"description"`
-	Status      string    `json:"status"`   // "pending", "in-progress", "completed", "cancelled"
-	Priority    string    `json:"priority"` // "low", "medium", "high", "critical"
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
-	AssignedTo  string    `json:"assigned_to,omitempty"`
-	Tags        []string  `json:"tags,omitempty"`
+	ID                int               `json:"id"`
+	Title             string            `json:"title"`
+	Description       string            `json:"description"`
+	Status            string            `json:"status"`   // "pending", "in-progress", "completed", "cancelled"
+	Priority          string            `json:"priority"` // "low", "medium", "high", "critical"
+	CreatedAt         time.Time         `json:"created_at"`
+	UpdatedAt         time.Time         `json:"updated_at"`
+	CompletedAt       *time.Time        `json:"completed_at,omitempty"`
+	DueDate           *time.Time        `json:"due_date,omitempty"`            // Deadline; overdue once past unless completed or cancelled.
+	StatusChangedAt   *time.Time        `json:"status_changed_at,omitempty"`   // Last update that changed Status.
+	AssigneeChangedAt *time.Time        `json:"assignee_changed_at,omitempty"` // Last update that changed AssignedTo.
+	AssignedTo        string            `json:"assigned_to,omitempty"`
+	Tags              []string          `json:"tags,omitempty"`
+	Metadata          map[string]string `json:"metadata,omitempty"` // Free-form key/value data, e.g. an issue link.
 }
 
 // TaskFilter represents filtering options for tasks.
 type TaskFilter struct {
-	Status     string   `json:"status,omitempty"`
-	Priority   string   `json:"priority,omitempty"`
-	AssignedTo string   `json:"assigned_to,omitempty"`
-	Tags       []string `json:"tags,omitempty"`
-	Limit      int      `json:"limit,omitempty"`
-	Offset     int      `json:"offset,omitempty"`
+	Status           string            `json:"status,omitempty"`
+	Priority         string            `json:"priority,omitempty"`
+	AssignedTo       string            `json:"assigned_to,omitempty"`
+	MinPriority      string            `json:"min_priority,omitempty"`
+	Tags             []string          `json:"tags,omitempty"`
+	Metadata         map[string]string `json:"metadata,omitempty"`          // Every key must be present with exactly this value.
+	MetaKey          string            `json:"meta_key,omitempty"`          // Metadata key that must be present.
+	MetaValue        string            `json:"meta_value,omitempty"`        // Exact value required for MetaKey.
+	CreatedIn        string            `json:"created_in,omitempty"`        // Month the task was created in, e.g. "2024-03".
+	CreatedInWeek    string            `json:"created_in_week,omitempty"`   // ISO week the task was created in, e.g. "2024-W12".
+	Age              string            `json:"age,omitempty"`               // "today", "week" or "older" than this week.
+	CreatedFrom      time.Time         `json:"-"`                           // Resolved from CreatedIn/CreatedInWeek/Age; inclusive.
+	CreatedTo        time.Time         `json:"-"`                           // Resolved from CreatedIn/CreatedInWeek/Age; exclusive.
+	DueAfter         *time.Time        `json:"due_after,omitempty"`         // Tasks due at or after this time; tasks without a due date never match.
+	DueBefore        *time.Time        `json:"due_before,omitempty"`        // Tasks due before this time; tasks without a due date never match.
+	HasDueDate       *bool             `json:"has_due_date,omitempty"`      // Only tasks with (true) or without (false) a due date; nil matches both.
+	IncludeCompleted *bool             `json:"include_completed,omitempty"` // Include completed/cancelled tasks; nil uses features.hide_closed_tasks.
+	ExcludedStatuses []string          `json:"-"`                           // Resolved from IncludeCompleted.
+	Cursor           string            `json:"cursor,omitempty"`            // Opaque next_cursor from a previous page; replaces offset.
+	CursorCreatedAt  time.Time         `json:"-"`                           // Decoded from Cursor.
+	CursorID         int               `json:"-"`                           // Decoded from Cursor.
+	Limit            int               `json:"limit,omitempty"`
+	Offset           int               `json:"offset,omitempty"`
 }
 
 // TaskSearchQuery represents a search query for tasks.
 type TaskSearchQuery struct {
-	Query    string     `json:"query"`
-	Fields   []string   `json:"fields"` // Fields to search in: "title", "description"
-	Filters  TaskFilter `json:"filters"`
-	SortBy   string     `json:"sort_by"` // "created_at", "updated_at", "priority"
-	SortDesc bool       `json:"sort_desc"`
+	Query        string             `json:"query"`
+	Fields       []string           `json:"fields"`        // Fields to search in: "title", "description"
+	FieldWeights map[string]float64 `json:"field_weights"` // Positive relevance weight per field; missing fields weigh 1
+	Filters      TaskFilter         `json:"filters"`
+	SortBy       string             `json:"sort_by"` // "relevance", "created_at", "updated_at", "priority"
+	SortDesc     bool               `json:"sort_desc"`
+}
+
+// TaskSearchResult is the outcome of a search.
+type TaskSearchResult struct {
+	Tasks     []*Task          `json:"tasks"`
+	Matches   map[int][]string `json:"matches"`   // Fields each task matched, keyed by task ID.
+	Total     int              `json:"total"`     // Tasks matched before truncation.
+	Truncated bool             `json:"truncated"` // More tasks matched than the configured maximum.
 }
 
 // TaskStats provides statistics about tasks.
@@ -43,21 +74,51 @@ type TaskStats struct {
 	TasksByStatus   map[string]int `json:"tasks_by_status"`
 	TasksByPriority map[string]int `json:"tasks_by_priority"`
 	TasksByUser     map[string]int `json:"tasks_by_user"`
+	TasksByTag      map[string]int `json:"tasks_by_tag"`               // Keyed by trimmed, lowercased tag.
+	OtherUserTasks  int            `json:"other_user_tasks,omitempty"` // Assigned tasks left out of TasksByUser by TaskStatsOptions.
+	OverdueTasks    int            `json:"overdue_tasks"`              // Open tasks whose due date has passed.
 	LastUpdated     time.Time      `json:"last_updated"`
 }
 
+// TaskSummary combines the data a dashboard needs in one payload.
+type TaskSummary struct {
+	Stats           *TaskStats `json:"stats"`
+	RecentlyUpdated []*Task    `json:"recently_updated"`
+	TopTags         []TagCount `json:"top_tags"`
+}
+
+// TagCount is the number of tasks carrying a tag.
+type TagCount struct {
+	Tag   string `json:"tag"`
+	Count int    `json:"count"`
+}
+
+// TaskStatsOptions trims the per-user breakdown of TaskStats. Zero values
+// keep every assignee.
+type TaskStatsOptions struct {
+	TopUsers     int `json:"top_users"`      // Keep only the N assignees with the most tasks.
+	MinUserTasks int `json:"min_user_tasks"` // Drop assignees with fewer tasks than this.
+}
+
 // Validation methods for Task.
 
-// Validate checks if the task has valid data.
+// Validate checks if the task has valid data, using the default title and
+// description length limits.
 func (t *Task) Validate() error {
+	return t.ValidateWithLimits(200, 1000)
+}
+
+// ValidateWithLimits checks if the task has valid data, allowing titles of
+// up to maxTitle and descriptions of up to maxDescription characters.
+func (t *Task) ValidateWithLimits(maxTitle, maxDescription int) error {
 	if t.Title == "" {
 		return fmt.Errorf("task title is required")
 	}
-	if len(t.Title) > 200 {
-		return fmt.Errorf("task title must be less than 200 characters")
+	if len(t.Title) > maxTitle {
+		return fmt.Errorf("task title must be no more than %d characters", maxTitle)
 	}
-	if len(t.Description) > 1000 {
-		return fmt.Errorf("task description must be less than 1000 characters")
+	if len(t.Description) > maxDescription {
+		return fmt.Errorf("task description must be no more than %d characters", maxDescription)
 	}
 	if !IsValidStatus(t.Status) {
 		return fmt.Errorf("invalid task status: %s", t.Status)
@@ -90,6 +151,70 @@ func IsValidPriority(priority string) bool {
 	return false
 }
 
+// validStatusTransitions maps each status to the statuses a task may move
+// to from it. SetStatusTransitions replaces it at startup.
+var validStatusTransitions = map[string][]string{
+	"pending":     {"in-progress", "completed", "cancelled"},
+	"in-progress": {"pending", "completed", "cancelled"},
+	"completed":   {"in-progress"},
+	"cancelled":   {"pending"},
+}
+
+// SetStatusTransitions replaces the status workflow. A status missing from
+// transitions cannot be left. It must be called before requests are served.
+func SetStatusTransitions(transitions map[string][]string) {
+	validStatusTransitions = make(map[string][]string, len(transitions))
+	for from, to := range transitions {
+		validStatusTransitions[from] = append([]string(nil), to...)
+	}
+}
+
+// GetAllowedTransitions returns the statuses a task in status may move to.
+func GetAllowedTransitions(status string) []string {
+	return append([]string{}, validStatusTransitions[status]...)
+}
+
+// CanTransition reports whether a task may move from one status to another.
+// Keeping the same status is always allowed, and so is leaving a status
+// outside the known set, such as one imported with validation off.
+func CanTransition(from, to string) bool {
+	if from == to || !IsValidStatus(from) {
+		return true
+	}
+	for _, allowed := range validStatusTransitions[from] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
+// PriorityWeight returns the ordering weight of a priority, from 1 for
+// "low" up to 4 for "critical". Unknown priorities weigh 0.
+func PriorityWeight(priority string) int {
+	priorityOrder := map[string]int{"low": 1, "medium": 2, "high": 3, "critical": 4}
+	return priorityOrder[priority]
+}
+
+// IsOverdue reports whether the task is past its due date at now. Tasks
+// without a due date, and completed or cancelled tasks, are never overdue.
+func (t *Task) IsOverdue(now time.Time) bool {
+	if t.DueDate == nil || t.Status == "completed" || t.Status == "cancelled" {
+		return false
+	}
+	return t.DueDate.Before(now)
+}
+
+// TaskAge describes how long a task has been open, in human terms.
+type TaskAge struct {
+	ID                 int        `json:"id"`
+	CreatedAt          time.Time  `json:"created_at"`
+	Age                string     `json:"age"`
+	OpenFor            string     `json:"open_for,omitempty"`
+	CompletedAt        *time.Time `json:"completed_at,omitempty"`
+	CompletionDuration string     `json:"completion_duration,omitempty"`
+}
+
 // GetValidStatuses returns all valid task statuses.
 func GetValidStatuses() []string {
 	return []string{"pending", "in-progress", "completed", "cancelled"}
@@ -45,20 +45,38 @@ func (u *User) Validate() error {
 	return nil
 }
 
+// validRoles lists the user roles from least to most privileged, and
+// roleLevels maps each to its rank, starting at 1. SetValidRoles replaces
+// both at startup.
+var (
+	validRoles = []string{"viewer", "user", "admin"}
+	roleLevels = map[string]int{"viewer": 1, "user": 2, "admin": 3}
+)
+
+// SetValidRoles replaces the role hierarchy with roles, ordered from least
+// to most privileged. It must be called before requests are served.
+func SetValidRoles(roles []string) {
+	validRoles = append([]string(nil), roles...)
+	roleLevels = make(map[string]int, len(roles))
+	for i, role := range roles {
+		roleLevels[role] = i + 1
+	}
+}
+
 // IsValidRole checks if the role is valid.
 func IsValidRole(role string) bool {
-	validRoles := []string{"admin", "user", "viewer"}
-	for _, v := range validRoles {
-		if v == role {
-			return true
-		}
-	}
-	return false
+	return RoleLevel(role) > 0
 }
 
-// GetValidRoles returns all valid user roles.
+// GetValidRoles returns all valid user roles, least privileged first.
 func GetValidRoles() []string {
-	return []string{"admin", "user", "viewer"}
+	return append([]string(nil), validRoles...)
+}
+
+// RoleLevel returns the rank of a role in the hierarchy, higher meaning
+// more privileged. Unknown roles rank 0.
+func RoleLevel(role string) int {
+	return roleLevels[role]
 }
 
 // isValidEmail performs basic email validation.
@@ -0,0 +1,284 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"merge-queue/internal/models"
+)
+
+// PatchTask applies an RFC 6902 JSON Patch document to a task. The patch is
+// applied to a copy and only saved if every operation succeeds and the
+// result passes the model validation rules.
+func (ts *TaskService) PatchTask(id int, ops []models.JSONPatchOperation) (*models.Task, error) {
+	ts.mutex.Lock()
+	defer ts.mutex.Unlock()
+
+	task, exists := ts.tasks[id]
+	if !exists {
+		return nil, fmt.Errorf("task with ID %d %w", id, ErrTaskNotFound)
+	}
+
+	patched := *task
+	patched.Tags = append([]string(nil), task.Tags...)
+	patched.Metadata = copyMetadata(task.Metadata)
+
+	for i, op := range ops {
+		if err := applyPatchOperation(&patched, op); err != nil {
+			return nil, fmt.Errorf("patch operation %d (%s %s): %w", i, op.Op, op.Path, err)
+		}
+	}
+
+	patched.Status = ts.normalizeEnum(patched.Status)
+	patched.Priority = ts.normalizeEnum(patched.Priority)
+
+	if err := ts.validator.ValidateRequired("title", patched.Title); err != nil {
+		return nil, err
+	}
+	if patched.AssignedTo != task.AssignedTo {
+		if err := ts.checkAssignee(patched.AssignedTo); err != nil {
+			return nil, err
+		}
+	}
+	if err := ts.checkTransition(task.Status, patched.Status); err != nil {
+		return nil, err
+	}
+	err := ts.strict(func() error {
+		if err := patched.ValidateWithLimits(ts.config.Features.MaxTitleLength, ts.config.Features.MaxDescriptionLength); err != nil {
+			return err
+		}
+		if err := ts.validateTags(patched.Tags); err != nil {
+			return err
+		}
+		return ts.validateMetadata(patched.Metadata)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	trackCompletion(&patched, now)
+	trackFieldChanges(task, &patched, now)
+	patched.UpdatedAt = now
+	if err := ts.persist(&patched); err != nil {
+		return nil, err
+	}
+	*task = patched
+	ts.notifyChanged()
+
+	return task, nil
+}
+
+// applyPatchOperation applies a single JSON Patch operation to task.
+func applyPatchOperation(task *models.Task, op models.JSONPatchOperation) error {
+	segments := strings.Split(strings.TrimPrefix(op.Path, "/"), "/")
+	if !strings.HasPrefix(op.Path, "/") || segments[0] == "" {
+		return fmt.Errorf("invalid path")
+	}
+
+	switch segments[0] {
+	case "id", "created_at", "updated_at", "completed_at", "status_changed_at", "assignee_changed_at":
+		return fmt.Errorf("field is immutable")
+	case "tags":
+		return applyTagsPatch(task, op, segments[1:])
+	case "metadata":
+		return applyMetadataPatch(task, op, segments[1:])
+	case "due_date":
+		if len(segments) > 1 {
+			return fmt.Errorf("invalid path")
+		}
+		return applyDueDatePatch(task, op)
+	}
+
+	if len(segments) > 1 {
+		return fmt.Errorf("invalid path")
+	}
+
+	field, err := stringField(task, segments[0])
+	if err != nil {
+		return err
+	}
+
+	switch op.Op {
+	case "add", "replace":
+		return json.Unmarshal(op.Value, field)
+	case "remove":
+		*field = ""
+		return nil
+	case "test":
+		var expected string
+		if err := json.Unmarshal(op.Value, &expected); err != nil {
+			return err
+		}
+		if *field != expected {
+			return fmt.Errorf("test failed")
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported operation")
+	}
+}
+
+// stringField returns a pointer to the patchable string field named by a
+// JSON Patch path segment.
+func stringField(task *models.Task, name string) (*string, error) {
+	switch name {
+	case "title":
+		return &task.Title, nil
+	case "description":
+		return &task.Description, nil
+	case "status":
+		return &task.Status, nil
+	case "priority":
+		return &task.Priority, nil
+	case "assigned_to":
+		return &task.AssignedTo, nil
+	default:
+		return nil, fmt.Errorf("unknown field")
+	}
+}
+
+// applyDueDatePatch applies an operation to /due_date. A null value clears
+// the due date, as remove does.
+func applyDueDatePatch(task *models.Task, op models.JSONPatchOperation) error {
+	var value *time.Time
+	if op.Op != "remove" {
+		if err := json.Unmarshal(op.Value, &value); err != nil {
+			return err
+		}
+		if value != nil {
+			utc := value.UTC()
+			value = &utc
+		}
+	}
+
+	switch op.Op {
+	case "add", "replace":
+		task.DueDate = value
+	case "remove":
+		task.DueDate = nil
+	case "test":
+		if (value == nil) != (task.DueDate == nil) || (value != nil && !value.Equal(*task.DueDate)) {
+			return fmt.Errorf("test failed")
+		}
+	default:
+		return fmt.Errorf("unsupported operation")
+	}
+
+	return nil
+}
+
+// applyTagsPatch applies an operation to /tags or one of its elements.
+func applyTagsPatch(task *models.Task, op models.JSONPatchOperation, rest []string) error {
+	// Whole-array operations.
+	if len(rest) == 0 {
+		switch op.Op {
+		case "add", "replace":
+			return json.Unmarshal(op.Value, &task.Tags)
+		case "remove":
+			task.Tags = nil
+			return nil
+		default:
+			return fmt.Errorf("unsupported operation")
+		}
+	}
+
+	if len(rest) > 1 {
+		return fmt.Errorf("invalid path")
+	}
+
+	// Element operations: "-" appends, otherwise a zero-based index.
+	index := len(task.Tags)
+	if rest[0] != "-" {
+		i, err := strconv.Atoi(rest[0])
+		if err != nil || i < 0 || i > len(task.Tags) {
+			return fmt.Errorf("tag index out of range")
+		}
+		index = i
+	}
+
+	var tag string
+	switch op.Op {
+	case "add":
+		if err := json.Unmarshal(op.Value, &tag); err != nil {
+			return err
+		}
+		task.Tags = append(task.Tags[:index], append([]string{tag}, task.Tags[index:]...)...)
+	case "replace", "remove":
+		if index >= len(task.Tags) {
+			return fmt.Errorf("tag index out of range")
+		}
+		if op.Op == "remove" {
+			task.Tags = append(task.Tags[:index], task.Tags[index+1:]...)
+			return nil
+		}
+		if err := json.Unmarshal(op.Value, &tag); err != nil {
+			return err
+		}
+		task.Tags[index] = tag
+	default:
+		return fmt.Errorf("unsupported operation")
+	}
+
+	return nil
+}
+
+// applyMetadataPatch applies an operation to /metadata or one of its keys.
+func applyMetadataPatch(task *models.Task, op models.JSONPatchOperation, rest []string) error {
+	// Whole-object operations.
+	if len(rest) == 0 {
+		switch op.Op {
+		case "add", "replace":
+			task.Metadata = nil
+			return json.Unmarshal(op.Value, &task.Metadata)
+		case "remove":
+			task.Metadata = nil
+			return nil
+		default:
+			return fmt.Errorf("unsupported operation")
+		}
+	}
+
+	if len(rest) > 1 {
+		return fmt.Errorf("invalid path")
+	}
+
+	// JSON Pointer escapes "~1" for "/" and "~0" for "~".
+	key := strings.NewReplacer("~1", "/", "~0", "~").Replace(rest[0])
+	current, exists := task.Metadata[key]
+
+	switch op.Op {
+	case "add", "replace":
+		if op.Op == "replace" && !exists {
+			return fmt.Errorf("metadata key not found")
+		}
+		var value string
+		if err := json.Unmarshal(op.Value, &value); err != nil {
+			return err
+		}
+		if task.Metadata == nil {
+			task.Metadata = make(map[string]string)
+		}
+		task.Metadata[key] = value
+	case "remove":
+		if !exists {
+			return fmt.Errorf("metadata key not found")
+		}
+		delete(task.Metadata, key)
+	case "test":
+		var expected string
+		if err := json.Unmarshal(op.Value, &expected); err != nil {
+			return err
+		}
+		if !exists || current != expected {
+			return fmt.Errorf("test failed")
+		}
+	default:
+		return fmt.Errorf("unsupported operation")
+	}
+
+	return nil
+}
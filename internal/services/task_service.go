@@ -1,38 +1,117 @@
 package services
 
 import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"os"
+	"regexp"
 	"sort"
 	"strings"
 	"sync"
 	"time"
 
+	"merge-queue/internal/config"
 	"merge-queue/internal/models"
+	"merge-queue/internal/storage"
 	"merge-queue/pkg/utils"
 )
 
+// ErrTaskNotFound is wrapped by errors for operations on a task ID that
+// does not exist.
+var ErrTaskNotFound = errors.New("not found")
+
+// ErrReseedDisabled is returned by Reseed outside the development
+// environment.
+var ErrReseedDisabled = errors.New("reseeding sample data is only allowed in development")
+
+// ErrPersist is wrapped by errors for writes the task store rejected. The
+// write is not applied.
+var ErrPersist = errors.New("failed to persist tasks")
+
 // TaskService handles business logic for task operations.
 type TaskService struct {
+	config    *config.Config
 	tasks     map[int]*models.Task
 	nextID    int
 	mutex     sync.RWMutex
 	validator *utils.ValidationUtils
 	timeUtils *utils.TimeUtils
 	maxTasks  int
+	tagRegex  *regexp.Regexp    // Compiled features.tag_pattern; nil allows any tag.
+	changed   chan struct{}     // Closed and replaced on every write to wake WaitForChanges.
+	removedAt time.Time         // Last time tasks were deleted, for ListVersion.
+	store     storage.TaskStore // Written through on every change; nil keeps tasks in memory only.
+
+	seedErrors []error
+}
+
+// NewTaskService creates a new TaskService instance that keeps tasks in
+// memory only. A MaxTasksPerUser of 0 disables the task limit.
+func NewTaskService(cfg *config.Config) *TaskService {
+	service := newTaskService(cfg)
+
+	// Add sample data for demonstration.
+	if cfg.Features.SeedSampleData {
+		service.mutex.Lock()
+		service.addSampleTasks()
+		service.mutex.Unlock()
+	}
+
+	return service
+}
+
+// NewTaskServiceWithStore creates a TaskService that loads its tasks from
+// store and saves every change back to it. Sample data is only seeded, and
+// saved, when the store is empty.
+func NewTaskServiceWithStore(cfg *config.Config, store storage.TaskStore) (*TaskService, error) {
+	tasks, err := store.All()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load tasks: %w", err)
+	}
+
+	service := newTaskService(cfg)
+	service.store = store
+
+	service.mutex.Lock()
+	defer service.mutex.Unlock()
+
+	for _, task := range tasks {
+		service.tasks[task.ID] = task
+		if task.ID >= service.nextID {
+			service.nextID = task.ID + 1
+		}
+	}
+
+	if len(tasks) == 0 && cfg.Features.SeedSampleData {
+		for _, id := range service.addSampleTasks() {
+			if err := service.persist(service.tasks[id]); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return service, nil
 }
 
-// NewTaskService creates a new TaskService instance.
-func NewTaskService(maxTasks int) *TaskService {
+// newTaskService creates an empty TaskService.
+func newTaskService(cfg *config.Config) *TaskService {
 	service := &TaskService{
+		config:    cfg,
 		tasks:     make(map[int]*models.Task),
 		nextID:    1,
 		validator: utils.NewValidationUtils(),
 		timeUtils: utils.NewTimeUtils(),
-		maxTasks:  maxTasks,
+		maxTasks:  cfg.Features.MaxTasksPerUser,
+		changed:   make(chan struct{}),
 	}
 
-	// Add sample data for demonstration.
-	service.addSampleTasks()
+	// Config validation has already checked that the pattern compiles.
+	if cfg.Features.TagPattern != "" {
+		service.tagRegex = regexp.MustCompile("^(?:" + cfg.Features.TagPattern + ")$")
+	}
 
 	return service
 }
@@ -42,13 +121,842 @@ func (ts *TaskService) CreateTask(req *models.CreateTaskRequest) (*models.Task,
 	ts.mutex.Lock()
 	defer ts.mutex.Unlock()
 
+	task, err := ts.prepareTask(req)
+	if err != nil {
+		return nil, err
+	}
+
+	task.ID = ts.nextID
+	if err := ts.persist(task); err != nil {
+		return nil, err
+	}
+	ts.tasks[ts.nextID] = task
+	ts.nextID++
+	ts.notifyChanged()
+
+	return task, nil
+}
+
+// EnsureTask returns the task whose title matches req.Title, ignoring case
+// and surrounding or repeated whitespace, creating it from req if none
+// exists. The lookup and the insert happen under the same write lock, so
+// concurrent calls with the same title create at most one task. The
+// boolean reports whether a task was created.
+func (ts *TaskService) EnsureTask(req *models.CreateTaskRequest) (*models.Task, bool, error) {
+	ts.mutex.Lock()
+	defer ts.mutex.Unlock()
+
+	key := normalizeTitle(req.Title)
+	for _, task := range ts.tasks {
+		if normalizeTitle(task.Title) == key {
+			return task, false, nil
+		}
+	}
+
+	task, err := ts.prepareTask(req)
+	if err != nil {
+		return nil, false, err
+	}
+
+	task.ID = ts.nextID
+	if err := ts.persist(task); err != nil {
+		return nil, false, err
+	}
+	ts.tasks[ts.nextID] = task
+	ts.nextID++
+	ts.notifyChanged()
+
+	return task, true, nil
+}
+
+// PreviewTask runs the same validation as CreateTask and returns the task
+// that would be created, without storing it or consuming an ID.
+func (ts *TaskService) PreviewTask(req *models.CreateTaskRequest) (*models.Task, error) {
+	ts.mutex.RLock()
+	defer ts.mutex.RUnlock()
+
+	return ts.prepareTask(req)
+}
+
+// GetTask retrieves a task by ID.
+func (ts *TaskService) GetTask(id int) (*models.Task, error) {
+	ts.mutex.RLock()
+	defer ts.mutex.RUnlock()
+
+	task, exists := ts.tasks[id]
+	if !exists {
+		return nil, fmt.Errorf("task with ID %d %w", id, ErrTaskNotFound)
+	}
+
+	return task, nil
+}
+
+// GetAllTasks returns all tasks with optional filtering.
+func (ts *TaskService) GetAllTasks(filter *models.TaskFilter) ([]*models.Task, error) {
+	ts.mutex.RLock()
+	defer ts.mutex.RUnlock()
+
+	var tasks []*models.Task
+
+	for _, task := range ts.tasks {
+		if ts.matchesFilter(task, filter) {
+			tasks = append(tasks, task)
+		}
+	}
+
+	// Apply sorting.
+	ts.sortTasks(tasks)
+
+	// Seek past the cursor; tasks are ordered by (created_at, id) descending.
+	if filter != nil && filter.Cursor != "" {
+		start := sort.Search(len(tasks), func(i int) bool {
+			return isBeforeCursor(tasks[i], filter.CursorCreatedAt, filter.CursorID)
+		})
+		tasks = tasks[start:]
+	}
+
+	// Apply pagination.
+	if filter != nil && (filter.Limit > 0 || filter.Offset > 0) {
+		tasks = ts.applyPagination(tasks, filter.Limit, filter.Offset)
+	}
+
+	return tasks, nil
+}
+
+// ChangesSince returns the tasks updated after since, oldest change first,
+// and the server time of the snapshot. Clients pass that time as since on
+// their next poll; it is taken under the read lock, so no write can land
+// between it and the scan.
+func (ts *TaskService) ChangesSince(since time.Time) ([]*models.Task, time.Time) {
+	ts.mutex.RLock()
+	defer ts.mutex.RUnlock()
+
+	return ts.changesSince(since)
+}
+
+// WaitForChanges is ChangesSince for long polling: if nothing has changed
+// since since, it blocks until a task is updated or ctx is done, returning
+// no tasks in the latter case. The server time is always that of the
+// snapshot returned, so a change landing during the wait is picked up by
+// the next call.
+func (ts *TaskService) WaitForChanges(ctx context.Context, since time.Time) ([]*models.Task, time.Time) {
+	for {
+		ts.mutex.RLock()
+		tasks, now := ts.changesSince(since)
+		changed := ts.changed
+		ts.mutex.RUnlock()
+
+		if len(tasks) > 0 {
+			return tasks, now
+		}
+
+		select {
+		case <-ctx.Done():
+			return tasks, now
+		case <-changed:
+		}
+	}
+}
+
+// changesSince implements ChangesSince. The caller must hold the lock.
+func (ts *TaskService) changesSince(since time.Time) ([]*models.Task, time.Time) {
+	now := time.Now().UTC()
+
+	tasks := make([]*models.Task, 0)
+	for _, task := range ts.tasks {
+		if task.UpdatedAt.After(since) {
+			tasks = append(tasks, task)
+		}
+	}
+
+	sort.Slice(tasks, func(i, j int) bool {
+		return tasks[i].UpdatedAt.Before(tasks[j].UpdatedAt)
+	})
+
+	return tasks, now
+}
+
+// NextCursor returns the cursor for the page after tasks, a page returned
+// by GetAllTasks with the given limit, or "" if the page was not full.
+func (ts *TaskService) NextCursor(tasks []*models.Task, limit int) string {
+	if limit <= 0 || len(tasks) < limit {
+		return ""
+	}
+
+	last := tasks[len(tasks)-1]
+	raw := fmt.Sprintf("%d:%d", last.CreatedAt.UnixNano(), last.ID)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// ValidateFilter normalizes a filter parsed from client input and rejects
+// invalid values.
+func (ts *TaskService) ValidateFilter(filter *models.TaskFilter) error {
+	filter.Status = ts.normalizeEnum(filter.Status)
+	filter.Priority = ts.normalizeEnum(filter.Priority)
+	filter.MinPriority = ts.normalizeEnum(filter.MinPriority)
+
+	if filter.MinPriority != "" && !models.IsValidPriority(filter.MinPriority) {
+		return fmt.Errorf("invalid min_priority: %s", filter.MinPriority)
+	}
+
+	if filter.MetaValue != "" && filter.MetaKey == "" {
+		return fmt.Errorf("meta_value requires meta_key")
+	}
+
+	if filter.DueAfter != nil && filter.DueBefore != nil && !filter.DueAfter.Before(*filter.DueBefore) {
+		return fmt.Errorf("due_after must be before due_before")
+	}
+	if filter.HasDueDate != nil && !*filter.HasDueDate && (filter.DueAfter != nil || filter.DueBefore != nil) {
+		return fmt.Errorf("has_due_date=false cannot be combined with due_after or due_before")
+	}
+
+	// Closed tasks are hidden unless asked for, by the request or by
+	// config. An explicit status filter always wins.
+	includeCompleted := !ts.config.Features.HideClosedTasks
+	if filter.IncludeCompleted != nil {
+		includeCompleted = *filter.IncludeCompleted
+	}
+	filter.ExcludedStatuses = nil
+	if !includeCompleted && filter.Status == "" {
+		filter.ExcludedStatuses = []string{"completed", "cancelled"}
+	}
+
+	if filter.Cursor != "" {
+		if filter.Offset > 0 {
+			return fmt.Errorf("cursor cannot be combined with offset")
+		}
+		createdAt, id, err := decodeCursor(filter.Cursor)
+		if err != nil {
+			return err
+		}
+		filter.CursorCreatedAt, filter.CursorID = createdAt, id
+	}
+
+	return ts.resolveCreatedPeriod(filter)
+}
+
+// ValidateSearchQuery checks a search's fields, field weights and sort
+// against the configured allow-lists and validates its filters. Weights
+// must be positive. Repeated fields are dropped, so a field is never
+// searched or weighted twice.
+func (ts *TaskService) ValidateSearchQuery(query *models.TaskSearchQuery) error {
+	searchable := ts.config.Features.SearchableFields
+	for _, field := range query.Fields {
+		if err := ts.validator.ValidateOneOf("fields", field, searchable); err != nil {
+			return err
+		}
+	}
+	query.Fields = uniqueStrings(query.Fields)
+	for field, weight := range query.FieldWeights {
+		if err := ts.validator.ValidateOneOf("field_weights", field, searchable); err != nil {
+			return err
+		}
+		// Tasks are kept only when their score is positive, so a zero or
+		// negative weight could hide a real match.
+		if weight <= 0 {
+			return fmt.Errorf("field_weights.%s must be positive", field)
+		}
+	}
+
+	if query.SortBy != "" {
+		if err := ts.validator.ValidateOneOf("sort_by", query.SortBy, ts.config.Features.SortableFields); err != nil {
+			return err
+		}
+	}
+
+	return ts.ValidateFilter(&query.Filters)
+}
+
+// resolveCreatedPeriod turns the created_in and created_in_week periods and
+// the age bucket into the CreatedFrom/CreatedTo range. When several are
+// given the range is their intersection. Age buckets use the same day and
+// week boundaries as TimeUtils.IsToday and IsThisWeek.
+func (ts *TaskService) resolveCreatedPeriod(filter *models.TaskFilter) error {
+	filter.CreatedFrom, filter.CreatedTo = time.Time{}, time.Time{}
+
+	narrow := func(start, end time.Time) {
+		if filter.CreatedFrom.IsZero() || start.After(filter.CreatedFrom) {
+			filter.CreatedFrom = start
+		}
+		if filter.CreatedTo.IsZero() || end.Before(filter.CreatedTo) {
+			filter.CreatedTo = end
+		}
+	}
+
+	if filter.CreatedIn != "" {
+		start, end, err := ts.timeUtils.ParseMonth(filter.CreatedIn)
+		if err != nil {
+			return fmt.Errorf("invalid created_in: %w", err)
+		}
+		narrow(start, end)
+	}
+
+	if filter.CreatedInWeek != "" {
+		start, end, err := ts.timeUtils.ParseISOWeek(filter.CreatedInWeek)
+		if err != nil {
+			return fmt.Errorf("invalid created_in_week: %w", err)
+		}
+		narrow(start, end)
+	}
+
+	if filter.Age != "" {
+		now := time.Now()
+		today := ts.timeUtils.StartOfDay(now)
+		week := ts.timeUtils.StartOfWeek(now)
+
+		switch filter.Age {
+		case "today":
+			narrow(today, today.AddDate(0, 0, 1))
+		case "week":
+			narrow(week, week.AddDate(0, 0, 7))
+		case "older":
+			narrow(time.Time{}, week)
+		default:
+			return fmt.Errorf("invalid age: %s (expected today, week or older)", filter.Age)
+		}
+	}
+
+	return nil
+}
+
+// GetTaskAge describes how long the task has been open and, once
+// completed, how long it took.
+func (ts *TaskService) GetTaskAge(id int) (*models.TaskAge, error) {
+	task, err := ts.GetTask(id)
+	if err != nil {
+		return nil, err
+	}
+
+	age := &models.TaskAge{
+		ID:          task.ID,
+		CreatedAt:   task.CreatedAt,
+		Age:         ts.timeUtils.FormatRelativeTime(task.CreatedAt),
+		CompletedAt: task.CompletedAt,
+	}
+
+	if task.CompletedAt != nil {
+		age.CompletionDuration = ts.timeUtils.FormatDuration(task.CompletedAt.Sub(task.CreatedAt))
+	} else {
+		age.OpenFor = ts.timeUtils.FormatDuration(time.Since(task.CreatedAt))
+	}
+
+	return age, nil
+}
+
+// Count returns the number of tasks matching the filter. Pagination
+// fields on the filter are ignored.
+func (ts *TaskService) Count(filter *models.TaskFilter) int {
+	ts.mutex.RLock()
+	defer ts.mutex.RUnlock()
+
+	count := 0
+	for _, task := range ts.tasks {
+		if ts.matchesFilter(task, filter) {
+			count++
+		}
+	}
+
+	return count
+}
+
+// ListVersion summarizes the tasks matching filter, ignoring paging, for
+// cache validation: how many match and when the list last changed. The
+// time is the newest UpdatedAt among them or the last deletion of any task,
+// whichever is later, so removals also count as changes.
+func (ts *TaskService) ListVersion(filter *models.TaskFilter) (int, time.Time) {
+	ts.mutex.RLock()
+	defer ts.mutex.RUnlock()
+
+	count := 0
+	lastModified := ts.removedAt
+	for _, task := range ts.tasks {
+		if !ts.matchesFilter(task, filter) {
+			continue
+		}
+		count++
+		if task.UpdatedAt.After(lastModified) {
+			lastModified = task.UpdatedAt
+		}
+	}
+
+	return count, lastModified
+}
+
+// ValidateTask checks a create request against the same rules as
+// CreateTask and reports a pass/fail result for each field. It never
+// touches storage or allocates an ID.
+func (ts *TaskService) ValidateTask(req *models.CreateTaskRequest) *models.TaskValidationResult {
+	ts.normalizeCreateRequest(req)
+
+	result := &models.TaskValidationResult{
+		Valid:  true,
+		Fields: ts.checkCreateRequest(req),
+	}
+
+	for _, check := range result.Fields {
+		if !check.Valid {
+			result.Valid = false
+			break
+		}
+	}
+
+	return result
+}
+
+// SeedErrors returns the problems encountered while loading sample data,
+// such as an unreadable seed file or seed tasks that failed validation.
+func (ts *TaskService) SeedErrors() []error {
+	ts.mutex.RLock()
+	defer ts.mutex.RUnlock()
+
+	return ts.seedErrors
+}
+
+// Capacity returns the number of stored tasks and the configured maximum,
+// where a maximum of 0 means unlimited.
+func (ts *TaskService) Capacity() (used, max int) {
+	ts.mutex.RLock()
+	defer ts.mutex.RUnlock()
+
+	return len(ts.tasks), ts.maxTasks
+}
+
+// PingStore checks that the task store still accepts writes. It returns nil
+// when tasks are kept in memory only or the store has no such check.
+func (ts *TaskService) PingStore(ctx context.Context) error {
+	pinger, ok := ts.store.(storage.Pinger)
+	if !ok {
+		return nil
+	}
+	return pinger.Ping(ctx)
+}
+
+// DefaultPageSize returns the listing limit used when a request does not
+// give one.
+func (ts *TaskService) DefaultPageSize() int {
+	return ts.config.Defaults.PageSize
+}
+
+// UpdateTask applies a partial update to an existing task: only the fields
+// set in req change.
+func (ts *TaskService) UpdateTask(id int, req *models.UpdateTaskRequest) (*models.Task, error) {
+	return ts.updateTask(id, req, false)
+}
+
+// ReplaceTask replaces every editable field of an existing task, as PUT
+// does. Title, status and priority are required, so a replace never moves
+// a task to a status it did not ask for; the other fields are cleared when
+// left out. tags_add and tags_remove are not allowed.
+func (ts *TaskService) ReplaceTask(id int, req *models.UpdateTaskRequest) (*models.Task, error) {
+	if req.Title == nil {
+		return nil, fmt.Errorf("title is required")
+	}
+	if req.Status == nil {
+		return nil, fmt.Errorf("status is required")
+	}
+	if req.Priority == nil {
+		return nil, fmt.Errorf("priority is required")
+	}
+	if req.TagsAdd != nil || req.TagsRemove != nil {
+		return nil, fmt.Errorf("tags_add and tags_remove are only supported by PATCH")
+	}
+
+	full := *req
+	if full.Description == nil {
+		full.Description = new(string)
+	}
+	if full.AssignedTo == nil {
+		full.AssignedTo = new(string)
+	}
+	if full.Tags == nil {
+		full.Tags = []string{}
+	}
+	if full.Metadata == nil {
+		full.Metadata = map[string]string{}
+	}
+
+	return ts.updateTask(id, &full, true)
+}
+
+// updateTask implements UpdateTask and ReplaceTask. With replace set, the
+// due date is applied even when req leaves it out, clearing it.
+func (ts *TaskService) updateTask(id int, req *models.UpdateTaskRequest, replace bool) (*models.Task, error) {
+	ts.mutex.Lock()
+	defer ts.mutex.Unlock()
+
+	task, exists := ts.tasks[id]
+	if !exists {
+		return nil, fmt.Errorf("task with ID %d %w", id, ErrTaskNotFound)
+	}
+
+	// Validate update request.
+	ts.normalizeUpdateRequest(req)
+	if err := ts.validateUpdateRequest(req); err != nil {
+		return nil, err
+	}
+
+	// Validate the tag set that incremental tag edits would produce.
+	if req.TagsAdd != nil || req.TagsRemove != nil {
+		merged := ts.mergeTags(task.Tags, req.TagsAdd, req.TagsRemove)
+		if err := ts.strict(func() error { return ts.validateTags(merged) }); err != nil {
+			return nil, err
+		}
+	}
+
+	if req.Status != nil {
+		if err := ts.checkTransition(task.Status, *req.Status); err != nil {
+			return nil, err
+		}
+	}
+
+	// Apply updates.
+	previous := *task
+	if req.Title != nil {
+		task.Title = strings.TrimSpace(*req.Title)
+	}
+	if req.Description != nil {
+		task.Description = strings.TrimSpace(*req.Description)
+	}
+	if req.Status != nil {
+		task.Status = *req.Status
+	}
+	if req.Priority != nil {
+		task.Priority = *req.Priority
+	}
+	if req.AssignedTo != nil {
+		task.AssignedTo = strings.TrimSpace(*req.AssignedTo)
+	}
+	if req.Tags != nil {
+		task.Tags = req.Tags
+	}
+	if req.TagsAdd != nil || req.TagsRemove != nil {
+		task.Tags = ts.mergeTags(task.Tags, req.TagsAdd, req.TagsRemove)
+	}
+	if req.Metadata != nil {
+		task.Metadata = copyMetadata(req.Metadata)
+	}
+	if req.DueDate != nil || replace {
+		task.DueDate = copyTime(req.DueDate)
+	}
+
+	now := time.Now().UTC()
+	trackCompletion(task, now)
+	trackFieldChanges(&previous, task, now)
+	task.UpdatedAt = now
+
+	if err := ts.persist(task); err != nil {
+		*task = previous
+		return nil, err
+	}
+	ts.notifyChanged()
+
+	return task, nil
+}
+
+// Reseed deletes every task and loads the sample data again, restarting
+// IDs at 1. It returns the IDs of the seeded tasks; problems loading the
+// seed data are reported by SeedErrors as at startup. Reseed only runs in
+// development. If the store fails part way through, the error is
+// returned and the store may be left holding only some of the tasks.
+func (ts *TaskService) Reseed() ([]int, error) {
+	if !ts.config.IsDevelopment() {
+		return nil, ErrReseedDisabled
+	}
+
+	ts.mutex.Lock()
+	defer ts.mutex.Unlock()
+
+	for id := range ts.tasks {
+		if err := ts.unpersist(id); err != nil {
+			return nil, err
+		}
+	}
+
+	ts.tasks = make(map[int]*models.Task)
+	ts.nextID = 1
+	ts.seedErrors = nil
+	ts.removedAt = time.Now().UTC()
+
+	ids := ts.addSampleTasks()
+	ts.notifyChanged()
+
+	for _, id := range ids {
+		if err := ts.persist(ts.tasks[id]); err != nil {
+			return nil, err
+		}
+	}
+
+	return ids, nil
+}
+
+// DeleteTask removes a task by ID.
+func (ts *TaskService) DeleteTask(id int) error {
+	ts.mutex.Lock()
+	defer ts.mutex.Unlock()
+
+	if _, exists := ts.tasks[id]; !exists {
+		return fmt.Errorf("task with ID %d %w", id, ErrTaskNotFound)
+	}
+
+	if err := ts.unpersist(id); err != nil {
+		return err
+	}
+	delete(ts.tasks, id)
+	ts.removedAt = time.Now().UTC()
+	ts.notifyChanged()
+	return nil
+}
+
+// BulkUpdatePriority sets the priority of every task in ids under a single
+// write lock and reports the outcome per ID, in request order. Missing
+// tasks are reported without aborting the batch; an empty batch or invalid
+// priority fails the whole request.
+func (ts *TaskService) BulkUpdatePriority(ids []int, priority string) ([]models.BulkUpdateResult, error) {
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("task_ids must not be empty")
+	}
+
+	priority = ts.normalizeEnum(priority)
+	if err := ts.validator.ValidateRequired("priority", priority); err != nil {
+		return nil, err
+	}
+	if err := ts.strict(func() error {
+		return ts.validator.ValidateOneOf("priority", priority, models.GetValidPriorities())
+	}); err != nil {
+		return nil, err
+	}
+
+	ts.mutex.Lock()
+	defer ts.mutex.Unlock()
+
+	now := time.Now().UTC()
+	results := make([]models.BulkUpdateResult, 0, len(ids))
+	changed := false
+	for _, id := range ids {
+		task, exists := ts.tasks[id]
+		if !exists {
+			results = append(results, models.BulkUpdateResult{ID: id, Error: "task not found"})
+			continue
+		}
+
+		updated := *task
+		updated.Priority = priority
+		updated.UpdatedAt = now
+		if err := ts.persist(&updated); err != nil {
+			results = append(results, models.BulkUpdateResult{ID: id, Error: ErrPersist.Error()})
+			continue
+		}
+
+		*task = updated
+		changed = true
+		results = append(results, models.BulkUpdateResult{ID: id, Updated: true})
+	}
+
+	if changed {
+		ts.notifyChanged()
+	}
+	return results, nil
+}
+
+// SearchTasks searches for tasks based on query. With a search term and no
+// explicit sort, results are ordered by weighted relevance. The fields the
+// term matched are returned keyed by task ID. Once sorted, results beyond
+// features.max_search_results are dropped and the result marked truncated.
+func (ts *TaskService) SearchTasks(query *models.TaskSearchQuery) (*models.TaskSearchResult, error) {
+	ts.mutex.RLock()
+	defer ts.mutex.RUnlock()
+
+	var results []*models.Task
+	scores := make(map[int]float64)
+	matches := make(map[int][]string)
+	searchTerm := strings.ToLower(strings.TrimSpace(query.Query))
+	fields := uniqueStrings(query.Fields)
+
+	for _, task := range ts.tasks {
+		// Check if task matches filter criteria.
+		if !ts.matchesFilter(task, &query.Filters) {
+			continue
+		}
+
+		// Check if task matches search query.
+		score, matched := ts.searchScore(task, searchTerm, fields, query.FieldWeights)
+		if score > 0 {
+			results = append(results, task)
+			scores[task.ID] = score
+			if len(matched) > 0 {
+				matches[task.ID] = matched
+			}
+		}
+	}
+
+	// Apply sorting.
+	if searchTerm != "" && (query.SortBy == "" || query.SortBy == "relevance") {
+		ts.sortByRelevance(results, scores)
+	} else {
+		ts.sortTasksBy(results, query.SortBy, query.SortDesc)
+	}
+
+	total := len(results)
+	truncated := false
+	if limit := ts.config.Features.MaxSearchResults; limit > 0 && len(results) > limit {
+		for _, task := range results[limit:] {
+			delete(matches, task.ID)
+		}
+		results = results[:limit]
+		truncated = true
+	}
+
+	return &models.TaskSearchResult{Tasks: results, Matches: matches, Total: total, Truncated: truncated}, nil
+}
+
+// GetTaskStats returns statistics about tasks, trimming the per-user
+// breakdown according to opts.
+func (ts *TaskService) GetTaskStats(opts models.TaskStatsOptions) *models.TaskStats {
+	ts.mutex.RLock()
+	defer ts.mutex.RUnlock()
+
+	return ts.taskStats(opts)
+}
+
+// taskStats computes GetTaskStats. Callers must hold the mutex.
+func (ts *TaskService) taskStats(opts models.TaskStatsOptions) *models.TaskStats {
+	stats := &models.TaskStats{
+		TotalTasks:      len(ts.tasks),
+		TasksByStatus:   make(map[string]int),
+		TasksByPriority: make(map[string]int),
+		TasksByUser:     make(map[string]int),
+		TasksByTag:      make(map[string]int),
+		LastUpdated:     time.Now().UTC(),
+	}
+
+	for _, task := range ts.tasks {
+		stats.TasksByStatus[task.Status]++
+		stats.TasksByPriority[task.Priority]++
+		if task.AssignedTo != "" {
+			stats.TasksByUser[task.AssignedTo]++
+		}
+		for _, tag := range uniqueStrings(normalizeTags(task.Tags)) {
+			stats.TasksByTag[tag]++
+		}
+		if task.IsOverdue(stats.LastUpdated) {
+			stats.OverdueTasks++
+		}
+	}
+
+	trimUserStats(stats, opts)
+
+	return stats
+}
+
+// Helper methods.
+
+// GetSummary returns task statistics, the recentLimit most recently updated
+// tasks and the tagLimit most used tags, all taken from one snapshot. The
+// recent tasks are copies, so later updates do not change them.
+func (ts *TaskService) GetSummary(recentLimit, tagLimit int) *models.TaskSummary {
+	ts.mutex.RLock()
+	defer ts.mutex.RUnlock()
+
+	all := make([]*models.Task, 0, len(ts.tasks))
+	for _, task := range ts.tasks {
+		all = append(all, task)
+	}
+	ts.sortTasksBy(all, "updated_at", true)
+	if len(all) > recentLimit {
+		all = all[:recentLimit]
+	}
+
+	recent := make([]*models.Task, 0, len(all))
+	for _, task := range all {
+		copied := *task
+		recent = append(recent, &copied)
+	}
+
+	return &models.TaskSummary{
+		Stats:           ts.taskStats(models.TaskStatsOptions{}),
+		RecentlyUpdated: recent,
+		TopTags:         ts.tagCounts(tagLimit, 0),
+	}
+}
+
+// GetTagCounts returns how many tasks carry each tag, most used first and
+// ties broken by name. Tags are trimmed and lowercased, as in TaskStats.
+// Tags used by fewer than minCount tasks are dropped, and a positive limit
+// caps the number of entries.
+func (ts *TaskService) GetTagCounts(limit, minCount int) []models.TagCount {
+	ts.mutex.RLock()
+	defer ts.mutex.RUnlock()
+
+	return ts.tagCounts(limit, minCount)
+}
+
+// tagCounts computes GetTagCounts. Callers must hold the mutex.
+func (ts *TaskService) tagCounts(limit, minCount int) []models.TagCount {
+	counts := make(map[string]int)
+	for _, task := range ts.tasks {
+		for _, tag := range uniqueStrings(normalizeTags(task.Tags)) {
+			counts[tag]++
+		}
+	}
+
+	tags := make([]models.TagCount, 0, len(counts))
+	for tag, count := range counts {
+		if count >= minCount {
+			tags = append(tags, models.TagCount{Tag: tag, Count: count})
+		}
+	}
+
+	sort.Slice(tags, func(i, j int) bool {
+		if tags[i].Count != tags[j].Count {
+			return tags[i].Count > tags[j].Count
+		}
+		return tags[i].Tag < tags[j].Tag
+	})
+
+	if limit > 0 && len(tags) > limit {
+		tags = tags[:limit]
+	}
+
+	return tags
+}
+
+// trimUserStats removes assignees below opts.MinUserTasks and keeps only the
+// opts.TopUsers busiest, breaking ties by name. Removed counts are summed
+// into OtherUserTasks.
+func trimUserStats(stats *models.TaskStats, opts models.TaskStatsOptions) {
+	users := make([]string, 0, len(stats.TasksByUser))
+	for user := range stats.TasksByUser {
+		users = append(users, user)
+	}
+	sort.Slice(users, func(i, j int) bool {
+		ci, cj := stats.TasksByUser[users[i]], stats.TasksByUser[users[j]]
+		if ci != cj {
+			return ci > cj
+		}
+		return users[i] < users[j]
+	})
+
+	for i, user := range users {
+		count := stats.TasksByUser[user]
+		if count < opts.MinUserTasks || (opts.TopUsers > 0 && i >= opts.TopUsers) {
+			stats.OtherUserTasks += count
+			delete(stats.TasksByUser, user)
+		}
+	}
+}
+
+// prepareTask validates the request and builds an unsaved task from it.
+// Callers must hold the mutex.
+func (ts *TaskService) prepareTask(req *models.CreateTaskRequest) (*models.Task, error) {
+	ts.normalizeCreateRequest(req)
+
 	// Validate request.
 	if err := ts.validateCreateRequest(req); err != nil {
 		return nil, err
 	}
 
-	// Check task limit.
-	if len(ts.tasks) >= ts.maxTasks {
+	// Check task limit (0 means unlimited).
+	if ts.maxTasks > 0 && len(ts.tasks) >= ts.maxTasks {
 		return nil, fmt.Errorf("maximum number of tasks (%d) reached", ts.maxTasks)
 	}
 
@@ -63,209 +971,239 @@ func (ts *TaskService) CreateTask(req *models.CreateTaskRequest) (*models.Task,
 		priority = "medium"
 	}
 
-	// Create task.
+	now := time.Now().UTC()
+
 	task := &models.Task{
-		ID:          ts.nextID,
 		Title:       strings.TrimSpace(req.Title),
 		Description: strings.TrimSpace(req.Description),
 		Status:      status,
 		Priority:    priority,
-		CreatedAt:   time.Now(),
-		UpdatedAt:   time.Now(),
+		CreatedAt:   now,
+		UpdatedAt:   now,
 		AssignedTo:  strings.TrimSpace(req.AssignedTo),
 		Tags:        req.Tags,
+		Metadata:    copyMetadata(req.Metadata),
+		DueDate:     copyTime(req.DueDate),
 	}
-
-	ts.tasks[ts.nextID] = task
-	ts.nextID++
+	trackCompletion(task, now)
 
 	return task, nil
 }
 
-// GetTask retrieves a task by ID.
-func (ts *TaskService) GetTask(id int) (*models.Task, error) {
-	ts.mutex.RLock()
-	defer ts.mutex.RUnlock()
-
-	task, exists := ts.tasks[id]
-	if !exists {
-		return nil, fmt.Errorf("task with ID %d not found", id)
+// copyMetadata returns a copy of metadata with keys trimmed, or nil if it
+// is empty.
+func copyMetadata(metadata map[string]string) map[string]string {
+	if len(metadata) == 0 {
+		return nil
 	}
 
-	return task, nil
+	copied := make(map[string]string, len(metadata))
+	for key, value := range metadata {
+		copied[strings.TrimSpace(key)] = value
+	}
+	return copied
 }
 
-// GetAllTasks returns all tasks with optional filtering.
-func (ts *TaskService) GetAllTasks(filter *models.TaskFilter) ([]*models.Task, error) {
-	ts.mutex.RLock()
-	defer ts.mutex.RUnlock()
-
-	var tasks []*models.Task
-
-	for _, task := range ts.tasks {
-		if ts.matchesFilter(task, filter) {
-			tasks = append(tasks, task)
-		}
+// copyTime returns a copy of t in UTC, or nil if t is nil.
+func copyTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
 	}
+	copied := t.UTC()
+	return &copied
+}
 
-	// Apply sorting.
-	ts.sortTasks(tasks)
-
-	// Apply pagination.
-	if filter != nil && (filter.Limit > 0 || filter.Offset > 0) {
-		tasks = ts.applyPagination(tasks, filter.Limit, filter.Offset)
+// normalizeTags trims and lowercases tags so that stored variants such as
+// "Bug" and " bug" are counted as one tag.
+func normalizeTags(tags []string) []string {
+	normalized := make([]string, len(tags))
+	for i, tag := range tags {
+		normalized[i] = strings.ToLower(strings.TrimSpace(tag))
 	}
-
-	return tasks, nil
+	return normalized
 }
 
-// UpdateTask updates an existing task.
-func (ts *TaskService) UpdateTask(id int, req *models.UpdateTaskRequest) (*models.Task, error) {
-	ts.mutex.Lock()
-	defer ts.mutex.Unlock()
+// normalizeTitle reduces a title to the key used to decide whether two
+// tasks have the same title.
+func normalizeTitle(title string) string {
+	return strings.ToLower(strings.Join(strings.Fields(title), " "))
+}
 
-	task, exists := ts.tasks[id]
-	if !exists {
-		return nil, fmt.Errorf("task with ID %d not found", id)
+// trackCompletion stamps CompletedAt when a task becomes completed and
+// clears it when the task leaves the completed status.
+func trackCompletion(task *models.Task, now time.Time) {
+	if task.Status != "completed" {
+		task.CompletedAt = nil
+		return
 	}
-
-	// Validate update request.
-	if err := ts.validateUpdateRequest(req); err != nil {
-		return nil, err
+	if task.CompletedAt == nil {
+		completedAt := now
+		task.CompletedAt = &completedAt
 	}
+}
 
-	// Apply updates.
-	if req.Title != nil {
-		task.Title = strings.TrimSpace(*req.Title)
+// trackFieldChanges stamps StatusChangedAt and AssigneeChangedAt on task
+// when an update changed those fields relative to previous.
+func trackFieldChanges(previous, task *models.Task, now time.Time) {
+	if task.Status != previous.Status {
+		changedAt := now
+		task.StatusChangedAt = &changedAt
 	}
-	if req.Description != nil {
-		task.Description = strings.TrimSpace(*req.Description)
-	}
-	if req.Status != nil {
-		task.Status = *req.Status
-	}
-	if req.Priority != nil {
-		task.Priority = *req.Priority
+	if task.AssignedTo != previous.AssignedTo {
+		changedAt := now
+		task.AssigneeChangedAt = &changedAt
 	}
-	if req.AssignedTo != nil {
-		task.AssignedTo = strings.TrimSpace(*req.AssignedTo)
-	}
-	if req.Tags != nil {
-		task.Tags = req.Tags
-	}
-
-	task.UpdatedAt = time.Now()
-
-	return task, nil
 }
 
-// DeleteTask removes a task by ID.
-func (ts *TaskService) DeleteTask(id int) error {
-	ts.mutex.Lock()
-	defer ts.mutex.Unlock()
-
-	if _, exists := ts.tasks[id]; !exists {
-		return fmt.Errorf("task with ID %d not found", id)
+func (ts *TaskService) validateCreateRequest(req *models.CreateTaskRequest) error {
+	for _, check := range ts.checkCreateRequest(req) {
+		if !check.Valid {
+			return fmt.Errorf("%s", check.Message)
+		}
 	}
-
-	delete(ts.tasks, id)
 	return nil
 }
 
-// SearchTasks searches for tasks based on query.
-func (ts *TaskService) SearchTasks(query *models.TaskSearchQuery) ([]*models.Task, error) {
-	ts.mutex.RLock()
-	defer ts.mutex.RUnlock()
-
-	var results []*models.Task
-	searchTerm := strings.ToLower(strings.TrimSpace(query.Query))
-
-	for _, task := range ts.tasks {
-		// Check if task matches filter criteria.
-		if !ts.matchesFilter(task, &query.Filters) {
-			continue
-		}
-
-		// Check if task matches search query.
-		if ts.matchesSearchQuery(task, searchTerm, query.Fields) {
-			results = append(results, task)
-		}
+// checkCreateRequest runs every create rule and reports a result per field,
+// in the order the rules are applied.
+func (ts *TaskService) checkCreateRequest(req *models.CreateTaskRequest) []models.FieldValidation {
+	checks := []models.FieldValidation{
+		fieldResult("title", func() error {
+			if err := ts.validator.ValidateRequired("title", req.Title); err != nil {
+				return err
+			}
+			return ts.strict(func() error {
+				return ts.validator.ValidateLength("title", req.Title, 1, ts.config.Features.MaxTitleLength)
+			})
+		}),
+		fieldResult("description", ts.strictRule(func() error {
+			if req.Description == "" {
+				return nil
+			}
+			return ts.validator.ValidateLength("description", req.Description, 0, ts.config.Features.MaxDescriptionLength)
+		})),
+		fieldResult("status", ts.strictRule(func() error {
+			if req.Status != "" && !models.IsValidStatus(req.Status) {
+				return fmt.Errorf("invalid status: %s", req.Status)
+			}
+			return nil
+		})),
+		fieldResult("priority", ts.strictRule(func() error {
+			if req.Priority != "" && !models.IsValidPriority(req.Priority) {
+				return fmt.Errorf("invalid priority: %s", req.Priority)
+			}
+			return nil
+		})),
+		fieldResult("tags", ts.strictRule(func() error {
+			return ts.validateTags(req.Tags)
+		})),
+		fieldResult("metadata", ts.strictRule(func() error {
+			return ts.validateMetadata(req.Metadata)
+		})),
+		fieldResult("due_date", ts.strictRule(func() error {
+			if req.DueDate != nil && req.DueDate.Before(time.Now()) {
+				return fmt.Errorf("due_date must not be in the past")
+			}
+			return nil
+		})),
+		fieldResult("assigned_to", func() error {
+			return ts.checkAssignee(req.AssignedTo)
+		}),
 	}
 
-	// Apply sorting.
-	ts.sortTasksBy(results, query.SortBy, query.SortDesc)
-
-	return results, nil
+	return checks
 }
 
-// GetTaskStats returns statistics about tasks.
-func (ts *TaskService) GetTaskStats() *models.TaskStats {
-	ts.mutex.RLock()
-	defer ts.mutex.RUnlock()
-
-	stats := &models.TaskStats{
-		TotalTasks:      len(ts.tasks),
-		TasksByStatus:   make(map[string]int),
-		TasksByPriority: make(map[string]int),
-		TasksByUser:     make(map[string]int),
-		LastUpdated:     time.Now(),
-	}
-
-	for _, task := range ts.tasks {
-		stats.TasksByStatus[task.Status]++
-		stats.TasksByPriority[task.Priority]++
-		if task.AssignedTo != "" {
-			stats.TasksByUser[task.AssignedTo]++
-		}
+// strict runs rule only when features.enable_validation is on. With it off,
+// only the checks that keep data usable still apply: a title is required
+// and tags cannot be combined with tags_add/tags_remove. Title and
+// description lengths, status and priority values, and tag and metadata
+// limits are skipped.
+func (ts *TaskService) strict(rule func() error) error {
+	if !ts.config.Features.EnableValidation {
+		return nil
 	}
+	return rule()
+}
 
-	return stats
+// strictRule wraps rule so it only runs under strict validation.
+func (ts *TaskService) strictRule(rule func() error) func() error {
+	return func() error { return ts.strict(rule) }
 }
 
-// Helper methods.
+// fieldResult runs a single field rule and records its outcome.
+func fieldResult(field string, rule func() error) models.FieldValidation {
+	if err := rule(); err != nil {
+		return models.FieldValidation{Field: field, Valid: false, Message: err.Error()}
+	}
+	return models.FieldValidation{Field: field, Valid: true}
+}
 
-func (ts *TaskService) validateCreateRequest(req *models.CreateTaskRequest) error {
-	if err := ts.validator.ValidateRequired("title", req.Title); err != nil {
-		return err
+func (ts *TaskService) validateUpdateRequest(req *models.UpdateTaskRequest) error {
+	if req.Title != nil {
+		if err := ts.validator.ValidateRequired("title", *req.Title); err != nil {
+			return err
+		}
 	}
 
-	if err := ts.validator.ValidateLength("title", req.Title, 1, 200); err != nil {
-		return err
+	if req.Tags != nil && (req.TagsAdd != nil || req.TagsRemove != nil) {
+		return fmt.Errorf("tags cannot be combined with tags_add or tags_remove")
 	}
 
-	if req.Description != "" {
-		if err := ts.validator.ValidateLength("description", req.Description, 0, 1000); err != nil {
+	if req.AssignedTo != nil {
+		if err := ts.checkAssignee(*req.AssignedTo); err != nil {
 			return err
 		}
 	}
 
-	if req.Status != "" && !models.IsValidStatus(req.Status) {
-		return fmt.Errorf("invalid status: %s", req.Status)
-	}
+	return ts.strict(func() error { return ts.checkUpdateRequest(req) })
+}
 
-	if req.Priority != "" && !models.IsValidPriority(req.Priority) {
-		return fmt.Errorf("invalid priority: %s", req.Priority)
+// validateTags applies the tag count and length limits and
+// features.tag_pattern.
+func (ts *TaskService) validateTags(tags []string) error {
+	if err := ts.validator.ValidateTagList(tags, 10, 50); err != nil {
+		return err
 	}
+	return ts.validator.ValidateTagPattern(tags, ts.tagRegex)
+}
 
-	if err := ts.validator.ValidateTagList(req.Tags, 10, 50); err != nil {
-		return err
+// validateMetadata applies the features.max_metadata_* limits.
+func (ts *TaskService) validateMetadata(metadata map[string]string) error {
+	features := ts.config.Features
+	return ts.validator.ValidateMetadata(metadata, features.MaxMetadataKeys, features.MaxMetadataKeyLength, features.MaxMetadataValueLength)
+}
+
+// checkAssignee enforces features.require_assignee. Like the title check it
+// is a policy rather than a data-quality rule, so it applies even when
+// strict validation is off.
+func (ts *TaskService) checkAssignee(assignee string) error {
+	if !ts.config.Features.RequireAssignee {
+		return nil
 	}
+	return ts.validator.ValidateRequired("assigned_to", assignee)
+}
 
+// checkTransition enforces the status workflow. Like checkAssignee it is a
+// policy, so it applies even when strict validation is off.
+func (ts *TaskService) checkTransition(from, to string) error {
+	if !models.CanTransition(from, to) {
+		return fmt.Errorf("cannot transition from %s to %s", from, to)
+	}
 	return nil
 }
 
-func (ts *TaskService) validateUpdateRequest(req *models.UpdateTaskRequest) error {
+// checkUpdateRequest applies the update rules skipped when strict
+// validation is off.
+func (ts *TaskService) checkUpdateRequest(req *models.UpdateTaskRequest) error {
 	if req.Title != nil {
-		if err := ts.validator.ValidateRequired("title", *req.Title); err != nil {
-			return err
-		}
-		if err := ts.validator.ValidateLength("title", *req.Title, 1, 200); err != nil {
+		if err := ts.validator.ValidateLength("title", *req.Title, 1, ts.config.Features.MaxTitleLength); err != nil {
 			return err
 		}
 	}
 
 	if req.Description != nil {
-		if err := ts.validator.ValidateLength("description", *req.Description, 0, 1000); err != nil {
+		if err := ts.validator.ValidateLength("description", *req.Description, 0, ts.config.Features.MaxDescriptionLength); err != nil {
 			return err
 		}
 	}
@@ -278,30 +1216,134 @@ func (ts *TaskService) validateUpdateRequest(req *models.UpdateTaskRequest) erro
 		return fmt.Errorf("invalid priority: %s", *req.Priority)
 	}
 
-	if err := ts.validator.ValidateTagList(req.Tags, 10, 50); err != nil {
+	if err := ts.validateTags(req.Tags); err != nil {
+		return err
+	}
+
+	if err := ts.validateTags(req.TagsAdd); err != nil {
+		return err
+	}
+
+	if err := ts.validateMetadata(req.Metadata); err != nil {
 		return err
 	}
 
 	return nil
 }
 
+// mergeTags returns current with the add tags appended and the remove tags
+// dropped, trimmed and without duplicates. current is not modified.
+func (ts *TaskService) mergeTags(current, add, remove []string) []string {
+	removed := make(map[string]bool, len(remove))
+	for _, tag := range remove {
+		removed[strings.TrimSpace(tag)] = true
+	}
+
+	seen := make(map[string]bool)
+	merged := make([]string, 0, len(current)+len(add))
+	for _, tag := range append(append([]string{}, current...), add...) {
+		tag = strings.TrimSpace(tag)
+		if removed[tag] || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		merged = append(merged, tag)
+	}
+
+	return merged
+}
+
+// normalizeEnum lowercases an enum value such as a status or priority when
+// case-insensitive matching is enabled.
+func (ts *TaskService) normalizeEnum(value string) string {
+	if !ts.config.Features.CaseInsensitiveMatching {
+		return value
+	}
+	return strings.ToLower(strings.TrimSpace(value))
+}
+
+// equalValues compares a stored value with a filter value, honoring the
+// case-insensitive matching setting.
+func (ts *TaskService) equalValues(stored, wanted string) bool {
+	if ts.config.Features.CaseInsensitiveMatching {
+		return strings.EqualFold(stored, wanted)
+	}
+	return stored == wanted
+}
+
+func (ts *TaskService) normalizeCreateRequest(req *models.CreateTaskRequest) {
+	req.Status = ts.normalizeEnum(req.Status)
+	req.Priority = ts.normalizeEnum(req.Priority)
+}
+
+func (ts *TaskService) normalizeUpdateRequest(req *models.UpdateTaskRequest) {
+	if req.Status != nil {
+		status := ts.normalizeEnum(*req.Status)
+		req.Status = &status
+	}
+	if req.Priority != nil {
+		priority := ts.normalizeEnum(*req.Priority)
+		req.Priority = &priority
+	}
+}
+
 func (ts *TaskService) matchesFilter(task *models.Task, filter *models.TaskFilter) bool {
 	if filter == nil {
 		return true
 	}
 
-	if filter.Status != "" && task.Status != filter.Status {
+	if filter.Status != "" && !ts.equalValues(task.Status, filter.Status) {
+		return false
+	}
+
+	for _, status := range filter.ExcludedStatuses {
+		if ts.equalValues(task.Status, status) {
+			return false
+		}
+	}
+
+	if filter.Priority != "" && !ts.equalValues(task.Priority, filter.Priority) {
 		return false
 	}
 
-	if filter.Priority != "" && task.Priority != filter.Priority {
+	if filter.AssignedTo != "" && !ts.equalValues(task.AssignedTo, filter.AssignedTo) {
 		return false
 	}
 
-	if filter.AssignedTo != "" && task.AssignedTo != filter.AssignedTo {
+	if filter.MinPriority != "" && models.PriorityWeight(task.Priority) < models.PriorityWeight(filter.MinPriority) {
 		return false
 	}
 
+	if !filter.CreatedFrom.IsZero() && task.CreatedAt.Before(filter.CreatedFrom) {
+		return false
+	}
+	if !filter.CreatedTo.IsZero() && !task.CreatedAt.Before(filter.CreatedTo) {
+		return false
+	}
+
+	if filter.DueAfter != nil && (task.DueDate == nil || task.DueDate.Before(*filter.DueAfter)) {
+		return false
+	}
+	if filter.DueBefore != nil && (task.DueDate == nil || !task.DueDate.Before(*filter.DueBefore)) {
+		return false
+	}
+	if filter.HasDueDate != nil && *filter.HasDueDate != (task.DueDate != nil) {
+		return false
+	}
+
+	if filter.MetaKey != "" {
+		value, ok := task.Metadata[filter.MetaKey]
+		if !ok || (filter.MetaValue != "" && value != filter.MetaValue) {
+			return false
+		}
+	}
+
+	for key, value := range filter.Metadata {
+		if actual, ok := task.Metadata[key]; !ok || actual != value {
+			return false
+		}
+	}
+
 	if len(filter.Tags) > 0 {
 		hasTag := false
 		for _, filterTag := range filter.Tags {
@@ -323,16 +1365,23 @@ func (ts *TaskService) matchesFilter(task *models.Task, filter *models.TaskFilte
 	return true
 }
 
-func (ts *TaskService) matchesSearchQuery(task *models.Task, searchTerm string, fields []string) bool {
+// searchScore returns the summed weight of the fields containing the
+// search term, or 0 if none match, along with the matching field names.
+// An empty term matches every task without naming any fields.
+func (ts *TaskService) searchScore(task *models.Task, searchTerm string, fields []string, weights map[string]float64) (float64, []string) {
 	if searchTerm == "" {
-		return true
+		return 1, nil
 	}
 
-	// If no fields specified, search in title and description.
+	// If no fields specified, search in title and description. Unknown
+	// fields are rejected by ValidateSearchQuery; any that reach here from
+	// other callers never match.
 	if len(fields) == 0 {
 		fields = []string{"title", "description"}
 	}
 
+	score := 0.0
+	var matched []string
 	for _, field := range fields {
 		var content string
 		switch field {
@@ -345,19 +1394,105 @@ func (ts *TaskService) matchesSearchQuery(task *models.Task, searchTerm string,
 		}
 
 		if strings.Contains(content, searchTerm) {
-			return true
+			weight, ok := weights[field]
+			if !ok {
+				weight = 1
+			}
+			score += weight
+			matched = append(matched, field)
 		}
 	}
 
-	return false
+	return score, matched
+}
+
+// persist saves task to the store, if there is one. The caller must hold
+// the write lock.
+func (ts *TaskService) persist(task *models.Task) error {
+	if ts.store == nil {
+		return nil
+	}
+	if err := ts.store.Save(task); err != nil {
+		return fmt.Errorf("%w: %v", ErrPersist, err)
+	}
+	return nil
+}
+
+// unpersist removes the task with id from the store, if there is one. The
+// caller must hold the write lock.
+func (ts *TaskService) unpersist(id int) error {
+	if ts.store == nil {
+		return nil
+	}
+	if err := ts.store.Delete(id); err != nil {
+		return fmt.Errorf("%w: %v", ErrPersist, err)
+	}
+	return nil
+}
+
+// notifyChanged wakes every pending WaitForChanges call. The caller must
+// hold the write lock.
+func (ts *TaskService) notifyChanged() {
+	close(ts.changed)
+	ts.changed = make(chan struct{})
+}
+
+// uniqueStrings returns values without repeats, keeping the first
+// occurrence of each.
+func uniqueStrings(values []string) []string {
+	if len(values) == 0 {
+		return values
+	}
+
+	seen := make(map[string]bool, len(values))
+	unique := make([]string, 0, len(values))
+	for _, value := range values {
+		if !seen[value] {
+			seen[value] = true
+			unique = append(unique, value)
+		}
+	}
+	return unique
 }
 
+// sortTasks orders tasks newest first, breaking creation-time ties by
+// descending ID so cursors have a total order to seek through.
 func (ts *TaskService) sortTasks(tasks []*models.Task) {
 	sort.Slice(tasks, func(i, j int) bool {
-		return tasks[i].CreatedAt.After(tasks[j].CreatedAt)
+		if !tasks[i].CreatedAt.Equal(tasks[j].CreatedAt) {
+			return tasks[i].CreatedAt.After(tasks[j].CreatedAt)
+		}
+		return tasks[i].ID > tasks[j].ID
 	})
 }
 
+// isBeforeCursor reports whether task sorts after the cursor position,
+// i.e. belongs on a later page.
+func isBeforeCursor(task *models.Task, createdAt time.Time, id int) bool {
+	if !task.CreatedAt.Equal(createdAt) {
+		return task.CreatedAt.Before(createdAt)
+	}
+	return task.ID < id
+}
+
+// decodeCursor parses a cursor produced by NextCursor.
+func decodeCursor(cursor string) (time.Time, int, error) {
+	invalid := fmt.Errorf("invalid cursor")
+
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return time.Time{}, 0, invalid
+	}
+
+	var nanos int64
+	var id int
+	if _, err := fmt.Sscanf(string(raw), "%d:%d", &nanos, &id); err != nil {
+		return time.Time{}, 0, invalid
+	}
+
+	return time.Unix(0, nanos).UTC(), id, nil
+}
+
 func (ts *TaskService) sortTasksBy(tasks []*models.Task, sortBy string, desc bool) {
 	switch sortBy {
 	case "created_at":
@@ -375,9 +1510,8 @@ func (ts *TaskService) sortTasksBy(tasks []*models.Task, sortBy string, desc boo
 			return tasks[i].UpdatedAt.Before(tasks[j].UpdatedAt)
 		})
 	case "priority":
-		priorityOrder := map[string]int{"low": 1, "medium": 2, "high": 3, "critical": 4}
 		sort.Slice(tasks, func(i, j int) bool {
-			pi, pj := priorityOrder[tasks[i].Priority], priorityOrder[tasks[j].Priority]
+			pi, pj := models.PriorityWeight(tasks[i].Priority), models.PriorityWeight(tasks[j].Priority)
 			if desc {
 				return pi > pj
 			}
@@ -388,6 +1522,16 @@ func (ts *TaskService) sortTasksBy(tasks []*models.Task, sortBy string, desc boo
 	}
 }
 
+func (ts *TaskService) sortByRelevance(tasks []*models.Task, scores map[int]float64) {
+	sort.Slice(tasks, func(i, j int) bool {
+		si, sj := scores[tasks[i].ID], scores[tasks[j].ID]
+		if si != sj {
+			return si > sj
+		}
+		return tasks[i].CreatedAt.After(tasks[j].CreatedAt)
+	})
+}
+
 func (ts *TaskService) applyPagination(tasks []*models.Task, limit, offset int) []*models.Task {
 	if offset >= len(tasks) {
 		return []*models.Task{}
@@ -401,8 +1545,61 @@ func (ts *TaskService) applyPagination(tasks []*models.Task, limit, offset int)
 	return tasks[offset:end]
 }
 
-func (ts *TaskService) addSampleTasks() {
-	sampleTasks := []*models.CreateTaskRequest{
+// addSampleTasks stores the seed file's tasks, or the built-in samples, and
+// returns their IDs. The caller must hold the write lock.
+func (ts *TaskService) addSampleTasks() []int {
+	sampleTasks, err := ts.loadSeedFile()
+	if err != nil {
+		ts.seedErrors = append(ts.seedErrors, err)
+	}
+	if sampleTasks == nil {
+		sampleTasks = builtinSampleTasks()
+	}
+
+	ids := make([]int, 0, len(sampleTasks))
+	for i, req := range sampleTasks {
+		task, err := ts.prepareTask(req)
+		if err != nil {
+			ts.seedErrors = append(ts.seedErrors, fmt.Errorf("seed task %d (%q): %w", i+1, req.Title, err))
+			continue
+		}
+
+		task.ID = ts.nextID
+		ts.tasks[ts.nextID] = task
+		ts.nextID++
+		ids = append(ids, task.ID)
+	}
+
+	return ids
+}
+
+// loadSeedFile reads seed tasks from the configured seed file. It returns
+// nil without error when no file is configured or the file is absent.
+func (ts *TaskService) loadSeedFile() ([]*models.CreateTaskRequest, error) {
+	path := ts.config.Features.SeedFile
+	if path == "" {
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read seed file %s: %w", path, err)
+	}
+
+	var tasks []*models.CreateTaskRequest
+	if err := json.Unmarshal(data, &tasks); err != nil {
+		return nil, fmt.Errorf("invalid JSON in seed file %s: %w", path, err)
+	}
+
+	return tasks, nil
+}
+
+// builtinSampleTasks returns the default demonstration tasks.
+func builtinSampleTasks() []*models.CreateTaskRequest {
+	return []*models.CreateTaskRequest{
 		{
 			Title:       "Setup project structure",
 			Description: "Create basic Go project layout with proper package organization",
@@ -435,8 +1632,4 @@ func (ts *TaskService) addSampleTasks() {
 			Tags:        []string{"docs", "documentation"},
 		},
 	}
-
-	for _, req := range sampleTasks {
-		ts.CreateTask(req)
-	}
 }
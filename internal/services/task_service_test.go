@@ -0,0 +1,454 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"merge-queue/internal/config"
+	"merge-queue/internal/models"
+	"merge-queue/internal/storage"
+)
+
+// newTestService returns an empty in-memory service built from the default
+// config after applying configure.
+func newTestService(t *testing.T, configure func(cfg *config.Config)) *TaskService {
+	t.Helper()
+
+	cfg, err := config.LoadConfig("")
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	cfg.Features.SeedSampleData = false
+	if configure != nil {
+		configure(cfg)
+	}
+	return NewTaskService(cfg)
+}
+
+func createTasks(t *testing.T, ts *TaskService, n int) {
+	t.Helper()
+
+	for i := 0; i < n; i++ {
+		if _, err := ts.CreateTask(&models.CreateTaskRequest{Title: fmt.Sprintf("Task %d", i)}); err != nil {
+			t.Fatalf("CreateTask(%d) error = %v", i, err)
+		}
+	}
+}
+
+func TestCreateTaskUnlimited(t *testing.T) {
+	ts := newTestService(t, func(cfg *config.Config) {
+		cfg.Features.MaxTasksPerUser = 0
+	})
+
+	createTasks(t, ts, 250)
+
+	if used, limit := ts.Capacity(); used != 250 || limit != 0 {
+		t.Fatalf("Capacity() = (%d, %d), want (250, 0)", used, limit)
+	}
+}
+
+func TestCreateTaskEnforcesCap(t *testing.T) {
+	ts := newTestService(t, func(cfg *config.Config) {
+		cfg.Features.MaxTasksPerUser = 3
+	})
+
+	createTasks(t, ts, 3)
+
+	_, err := ts.CreateTask(&models.CreateTaskRequest{Title: "One too many"})
+	if err == nil || !strings.Contains(err.Error(), "maximum number of tasks (3) reached") {
+		t.Fatalf("CreateTask() error = %v, want limit error", err)
+	}
+	if used, _ := ts.Capacity(); used != 3 {
+		t.Fatalf("Capacity() used = %d, want 3", used)
+	}
+}
+
+func TestUpdateTaskMetadataLimits(t *testing.T) {
+	ts := newTestService(t, func(cfg *config.Config) {
+		cfg.Features.MaxMetadataKeys = 2
+		cfg.Features.MaxMetadataValueLength = 5
+	})
+	task, err := ts.CreateTask(&models.CreateTaskRequest{Title: "Label me"})
+	if err != nil {
+		t.Fatalf("CreateTask() error = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		metadata map[string]string
+		wantErr  string
+	}{
+		{name: "within limits", metadata: map[string]string{"a": "12345", "b": "x"}},
+		{name: "too many keys", metadata: map[string]string{"a": "1", "b": "2", "c": "3"}, wantErr: "maximum of 2 metadata keys allowed"},
+		{name: "value too long", metadata: map[string]string{"a": "123456"}, wantErr: "exceeds maximum length of 5 characters"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ts.UpdateTask(task.ID, &models.UpdateTaskRequest{Metadata: tt.metadata})
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("UpdateTask() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("UpdateTask() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateSearchQueryWeights(t *testing.T) {
+	tests := []struct {
+		name    string
+		weights map[string]float64
+		wantErr string
+	}{
+		{name: "positive", weights: map[string]float64{"title": 2, "description": 0.5}},
+		{name: "zero", weights: map[string]float64{"title": 0}, wantErr: "field_weights.title must be positive"},
+		{name: "negative", weights: map[string]float64{"description": -1}, wantErr: "field_weights.description must be positive"},
+	}
+
+	ts := newTestService(t, nil)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ts.ValidateSearchQuery(&models.TaskSearchQuery{Query: "x", FieldWeights: tt.weights})
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("ValidateSearchQuery() error = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.wantErr {
+				t.Fatalf("ValidateSearchQuery() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTagCountsMatchStats(t *testing.T) {
+	ts := newTestService(t, nil)
+	for _, tags := range [][]string{{"Bug", "bug"}, {" bug "}, {"UI"}} {
+		if _, err := ts.CreateTask(&models.CreateTaskRequest{Title: "Tagged", Tags: tags}); err != nil {
+			t.Fatalf("CreateTask(%v) error = %v", tags, err)
+		}
+	}
+
+	stats := ts.GetTaskStats(models.TaskStatsOptions{})
+	counts := ts.GetTagCounts(0, 0)
+	if len(counts) != len(stats.TasksByTag) {
+		t.Fatalf("GetTagCounts() = %v, want the tags of %v", counts, stats.TasksByTag)
+	}
+	for _, count := range counts {
+		if stats.TasksByTag[count.Tag] != count.Count {
+			t.Fatalf("GetTagCounts() %s = %d, TasksByTag = %d", count.Tag, count.Count, stats.TasksByTag[count.Tag])
+		}
+	}
+	if stats.TasksByTag["bug"] != 2 {
+		t.Fatalf("TasksByTag[bug] = %d, want 2", stats.TasksByTag["bug"])
+	}
+}
+
+func TestGetSummaryConcurrentUpdates(t *testing.T) {
+	ts := newTestService(t, nil)
+	createTasks(t, ts, 20)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		priorities := []string{"low", "high"}
+		for i := 0; i < 200; i++ {
+			priority := priorities[i%2]
+			if _, err := ts.UpdateTask(i%20+1, &models.UpdateTaskRequest{Priority: &priority}); err != nil {
+				t.Errorf("UpdateTask() error = %v", err)
+				return
+			}
+		}
+	}()
+
+	for i := 0; i < 200; i++ {
+		summary := ts.GetSummary(5, 3)
+		if len(summary.RecentlyUpdated) != 5 || summary.Stats.TotalTasks != 20 {
+			t.Fatalf("GetSummary() = %d recent of %d tasks, want 5 of 20", len(summary.RecentlyUpdated), summary.Stats.TotalTasks)
+		}
+		for j := 1; j < len(summary.RecentlyUpdated); j++ {
+			if summary.RecentlyUpdated[j].UpdatedAt.After(summary.RecentlyUpdated[j-1].UpdatedAt) {
+				t.Fatalf("RecentlyUpdated is not ordered by updated_at")
+			}
+		}
+	}
+	<-done
+}
+
+func TestReseedOnlyInDevelopment(t *testing.T) {
+	tests := []struct {
+		environment string
+		wantErr     error
+	}{
+		{environment: "development"},
+		{environment: "staging", wantErr: ErrReseedDisabled},
+		{environment: "production", wantErr: ErrReseedDisabled},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.environment, func(t *testing.T) {
+			ts := newTestService(t, func(cfg *config.Config) {
+				cfg.App.Environment = tt.environment
+			})
+
+			_, err := ts.Reseed()
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Reseed() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateSearchQueryFields(t *testing.T) {
+	tests := []struct {
+		name       string
+		fields     []string
+		wantFields []string
+		wantErr    bool
+	}{
+		{name: "empty list searches the defaults", fields: nil, wantFields: nil},
+		{name: "duplicates dropped", fields: []string{"title", "description", "title"}, wantFields: []string{"title", "description"}},
+		{name: "all invalid", fields: []string{"owner", "notes"}, wantErr: true},
+		{name: "one invalid", fields: []string{"title", "owner"}, wantErr: true},
+	}
+
+	ts := newTestService(t, nil)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query := &models.TaskSearchQuery{Query: "x", Fields: tt.fields}
+			err := ts.ValidateSearchQuery(query)
+			if tt.wantErr {
+				if err == nil || !strings.HasPrefix(err.Error(), "fields must be one of") {
+					t.Fatalf("ValidateSearchQuery() error = %v, want a fields error", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ValidateSearchQuery() error = %v, want nil", err)
+			}
+			if strings.Join(query.Fields, ",") != strings.Join(tt.wantFields, ",") {
+				t.Fatalf("Fields = %v, want %v", query.Fields, tt.wantFields)
+			}
+		})
+	}
+}
+
+func TestSearchTasksFieldEdgeCases(t *testing.T) {
+	ts := newTestService(t, nil)
+	if _, err := ts.CreateTask(&models.CreateTaskRequest{Title: "Fix login", Description: "Login fails on retry"}); err != nil {
+		t.Fatalf("CreateTask() error = %v", err)
+	}
+	if _, err := ts.CreateTask(&models.CreateTaskRequest{Title: "Write docs", Description: "Cover the login flow"}); err != nil {
+		t.Fatalf("CreateTask() error = %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		fields      []string
+		wantCount   int
+		wantMatched []string // Fields matched by the first result.
+	}{
+		{name: "empty list searches title and description", fields: nil, wantCount: 2, wantMatched: []string{"title", "description"}},
+		{name: "duplicates count once", fields: []string{"title", "title"}, wantCount: 1, wantMatched: []string{"title"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query := &models.TaskSearchQuery{Query: "login", Fields: tt.fields}
+			if err := ts.ValidateSearchQuery(query); err != nil {
+				t.Fatalf("ValidateSearchQuery() error = %v", err)
+			}
+			result, err := ts.SearchTasks(query)
+			if err != nil {
+				t.Fatalf("SearchTasks() error = %v", err)
+			}
+			if len(result.Tasks) != tt.wantCount {
+				t.Fatalf("SearchTasks() returned %d tasks, want %d", len(result.Tasks), tt.wantCount)
+			}
+			matched := result.Matches[result.Tasks[0].ID]
+			if strings.Join(matched, ",") != strings.Join(tt.wantMatched, ",") {
+				t.Fatalf("first result matched %v, want %v", matched, tt.wantMatched)
+			}
+		})
+	}
+}
+
+func TestPatchTaskDueDate(t *testing.T) {
+	ts := newTestService(t, nil)
+	task, err := ts.CreateTask(&models.CreateTaskRequest{Title: "Schedule me"})
+	if err != nil {
+		t.Fatalf("CreateTask() error = %v", err)
+	}
+
+	patch := func(op, value string) error {
+		_, err := ts.PatchTask(task.ID, []models.JSONPatchOperation{{Op: op, Path: "/due_date", Value: json.RawMessage(value)}})
+		return err
+	}
+
+	if err := patch("add", `"2030-01-02T10:00:00+02:00"`); err != nil {
+		t.Fatalf("add /due_date error = %v", err)
+	}
+	want := time.Date(2030, 1, 2, 8, 0, 0, 0, time.UTC)
+	if task.DueDate == nil || !task.DueDate.Equal(want) || task.DueDate.Location() != time.UTC {
+		t.Fatalf("DueDate = %v, want %v in UTC", task.DueDate, want)
+	}
+
+	if err := patch("test", `"2030-01-02T08:00:00Z"`); err != nil {
+		t.Fatalf("test /due_date error = %v", err)
+	}
+	if err := patch("test", `null`); err == nil {
+		t.Fatal("test /due_date null error = nil, want a failed test")
+	}
+
+	if err := patch("remove", ``); err != nil {
+		t.Fatalf("remove /due_date error = %v", err)
+	}
+	if task.DueDate != nil {
+		t.Fatalf("DueDate = %v after remove, want nil", task.DueDate)
+	}
+
+	if err := patch("replace", `"tomorrow"`); err == nil {
+		t.Fatal("replace /due_date with a non-timestamp error = nil, want an error")
+	}
+}
+
+func TestHasDueDateFilter(t *testing.T) {
+	ts := newTestService(t, nil)
+	due := time.Now().Add(24 * time.Hour)
+	if _, err := ts.CreateTask(&models.CreateTaskRequest{Title: "Scheduled", DueDate: &due}); err != nil {
+		t.Fatalf("CreateTask() error = %v", err)
+	}
+	if _, err := ts.CreateTask(&models.CreateTaskRequest{Title: "Unscheduled"}); err != nil {
+		t.Fatalf("CreateTask() error = %v", err)
+	}
+
+	yes, no := true, false
+	tests := []struct {
+		name      string
+		filter    models.TaskFilter
+		wantTitle string
+		wantErr   bool
+	}{
+		{name: "with due date", filter: models.TaskFilter{HasDueDate: &yes}, wantTitle: "Scheduled"},
+		{name: "without due date", filter: models.TaskFilter{HasDueDate: &no}, wantTitle: "Unscheduled"},
+		{name: "without due date in a window", filter: models.TaskFilter{HasDueDate: &no, DueBefore: &due}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter := tt.filter
+			err := ts.ValidateFilter(&filter)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("ValidateFilter() error = nil, want an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ValidateFilter() error = %v", err)
+			}
+
+			tasks, err := ts.GetAllTasks(&filter)
+			if err != nil {
+				t.Fatalf("GetAllTasks() error = %v", err)
+			}
+			if len(tasks) != 1 || tasks[0].Title != tt.wantTitle {
+				t.Fatalf("GetAllTasks() = %d tasks, want only %q", len(tasks), tt.wantTitle)
+			}
+		})
+	}
+}
+
+func TestReplaceTaskRequiredFields(t *testing.T) {
+	ts := newTestService(t, nil)
+	status := "completed"
+	task, err := ts.CreateTask(&models.CreateTaskRequest{Title: "Done", Status: status})
+	if err != nil {
+		t.Fatalf("CreateTask() error = %v", err)
+	}
+
+	title, priority := "Done again", "high"
+	tests := []struct {
+		name    string
+		req     models.UpdateTaskRequest
+		wantErr string
+	}{
+		{name: "missing title", req: models.UpdateTaskRequest{Status: &status, Priority: &priority}, wantErr: "title is required"},
+		{name: "missing status", req: models.UpdateTaskRequest{Title: &title, Priority: &priority}, wantErr: "status is required"},
+		{name: "missing priority", req: models.UpdateTaskRequest{Title: &title, Status: &status}, wantErr: "priority is required"},
+		{name: "complete", req: models.UpdateTaskRequest{Title: &title, Status: &status, Priority: &priority}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			replaced, err := ts.ReplaceTask(task.ID, &tt.req)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("ReplaceTask() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ReplaceTask() error = %v", err)
+			}
+			if replaced.Status != status || replaced.Title != title {
+				t.Fatalf("ReplaceTask() = %+v, want the completed task renamed", replaced)
+			}
+		})
+	}
+}
+
+func TestPingStore(t *testing.T) {
+	ts := newTestService(t, nil)
+	if err := ts.PingStore(context.Background()); err != nil {
+		t.Fatalf("PingStore() without a store = %v, want nil", err)
+	}
+
+	dir := t.TempDir()
+	store, err := storage.NewJSONFileStore(filepath.Join(dir, "tasks.json"))
+	if err != nil {
+		t.Fatalf("NewJSONFileStore() error = %v", err)
+	}
+	cfg, err := config.LoadConfig("")
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	cfg.Features.SeedSampleData = false
+	ts, err = NewTaskServiceWithStore(cfg, store)
+	if err != nil {
+		t.Fatalf("NewTaskServiceWithStore() error = %v", err)
+	}
+
+	if err := ts.PingStore(context.Background()); err != nil {
+		t.Fatalf("PingStore() = %v, want nil", err)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("ReadDir() error = %v", err)
+	}
+	for _, entry := range entries {
+		if strings.HasSuffix(entry.Name(), ".ping") {
+			t.Errorf("PingStore() left %s behind", entry.Name())
+		}
+	}
+
+	if err := os.RemoveAll(dir); err != nil {
+		t.Fatalf("RemoveAll() error = %v", err)
+	}
+	if err := ts.PingStore(context.Background()); err == nil {
+		t.Error("PingStore() with the store directory removed = nil, want error")
+	}
+}
@@ -0,0 +1,127 @@
+package storage
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"merge-queue/internal/models"
+)
+
+// encryptedPrefix marks a field value encrypted by fieldCipher. Values
+// without it are plaintext, so a store written before a key was set can
+// still be opened and is encrypted on its next write.
+const encryptedPrefix = "enc:v1:"
+
+// ErrEncryptedStore is returned when a store holds encrypted fields but was
+// opened without a key.
+var ErrEncryptedStore = errors.New("task store is encrypted but no encryption key is configured")
+
+// fieldCipher encrypts task descriptions and metadata values with AES-GCM.
+// Each value is bound to its task ID and field, so ciphertext copied
+// between tasks or fields fails to decrypt.
+type fieldCipher struct {
+	aead cipher.AEAD
+}
+
+// newFieldCipher returns a cipher for a 16, 24 or 32 byte AES key.
+func newFieldCipher(key []byte) (*fieldCipher, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid encryption key: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("invalid encryption key: %w", err)
+	}
+	return &fieldCipher{aead: aead}, nil
+}
+
+// encryptTask encrypts the sensitive fields of task in place. The task
+// must be a copy whose Metadata map is not shared with the caller's.
+func (fc *fieldCipher) encryptTask(task *models.Task) error {
+	description, err := fc.encrypt(task.Description, fieldData(task.ID, "description"))
+	if err != nil {
+		return err
+	}
+	task.Description = description
+
+	if task.Metadata == nil {
+		return nil
+	}
+	metadata := make(map[string]string, len(task.Metadata))
+	for key, value := range task.Metadata {
+		metadata[key], err = fc.encrypt(value, fieldData(task.ID, "metadata."+key))
+		if err != nil {
+			return err
+		}
+	}
+	task.Metadata = metadata
+	return nil
+}
+
+// decryptTask decrypts the sensitive fields of task in place. Plaintext
+// values are left as they are. A nil cipher fails on any encrypted value.
+func (fc *fieldCipher) decryptTask(task *models.Task) error {
+	description, err := fc.decrypt(task.Description, fieldData(task.ID, "description"))
+	if err != nil {
+		return fmt.Errorf("task %d description: %w", task.ID, err)
+	}
+	task.Description = description
+
+	for key, value := range task.Metadata {
+		task.Metadata[key], err = fc.decrypt(value, fieldData(task.ID, "metadata."+key))
+		if err != nil {
+			return fmt.Errorf("task %d metadata %q: %w", task.ID, key, err)
+		}
+	}
+	return nil
+}
+
+// Helper methods.
+
+// encrypt seals value with a random nonce. Empty values stay empty.
+func (fc *fieldCipher) encrypt(value string, additionalData []byte) (string, error) {
+	if value == "" {
+		return "", nil
+	}
+
+	nonce := make([]byte, fc.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("failed to encrypt task field: %w", err)
+	}
+	sealed := fc.aead.Seal(nonce, nonce, []byte(value), additionalData)
+	return encryptedPrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// decrypt opens a value produced by encrypt. Values without the encrypted
+// prefix are returned unchanged.
+func (fc *fieldCipher) decrypt(value string, additionalData []byte) (string, error) {
+	encoded, ok := strings.CutPrefix(value, encryptedPrefix)
+	if !ok {
+		return value, nil
+	}
+	if fc == nil {
+		return "", ErrEncryptedStore
+	}
+
+	sealed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(sealed) < fc.aead.NonceSize() {
+		return "", fmt.Errorf("malformed encrypted value")
+	}
+	nonce, ciphertext := sealed[:fc.aead.NonceSize()], sealed[fc.aead.NonceSize():]
+	plaintext, err := fc.aead.Open(nil, nonce, ciphertext, additionalData)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt (wrong encryption key?): %w", err)
+	}
+	return string(plaintext), nil
+}
+
+// fieldData is the additional authenticated data for one field of a task.
+func fieldData(id int, field string) []byte {
+	return []byte(fmt.Sprintf("task/%d/%s", id, field))
+}
@@ -0,0 +1,225 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"merge-queue/internal/models"
+)
+
+// JSONFileStore keeps tasks in memory and rewrites them to a single JSON
+// file on every change. The file is replaced atomically, so a crash leaves
+// either the old or the new contents. A write that fails leaves both the
+// file and the store unchanged.
+type JSONFileStore struct {
+	path   string
+	cipher *fieldCipher // Encrypts descriptions and metadata values on disk; nil writes plaintext.
+	mutex  sync.RWMutex
+	tasks  map[int]*models.Task
+}
+
+// NewJSONFileStore opens the store at path, loading any tasks already
+// saved there. A missing file is treated as an empty store and created on
+// the first write.
+func NewJSONFileStore(path string) (*JSONFileStore, error) {
+	return NewEncryptedJSONFileStore(path, nil)
+}
+
+// NewEncryptedJSONFileStore opens the store at path like NewJSONFileStore,
+// encrypting task descriptions and metadata values on disk with AES-GCM
+// under key (16, 24 or 32 bytes). A nil key stores plaintext. Plaintext
+// values already in the file are read as they are and encrypted on the
+// next write.
+func NewEncryptedJSONFileStore(path string, key []byte) (*JSONFileStore, error) {
+	fs := &JSONFileStore{
+		path:  path,
+		tasks: make(map[int]*models.Task),
+	}
+	if key != nil {
+		fc, err := newFieldCipher(key)
+		if err != nil {
+			return nil, err
+		}
+		fs.cipher = fc
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return fs, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read task store %s: %w", path, err)
+	}
+
+	var tasks []*models.Task
+	if err := json.Unmarshal(data, &tasks); err != nil {
+		return nil, fmt.Errorf("failed to parse task store %s: %w", path, err)
+	}
+	for _, task := range tasks {
+		if err := fs.cipher.decryptTask(task); err != nil {
+			return nil, fmt.Errorf("failed to read task store %s: %w", path, err)
+		}
+		fs.tasks[task.ID] = task
+	}
+
+	return fs, nil
+}
+
+// Save inserts or replaces the task with task.ID.
+func (fs *JSONFileStore) Save(task *models.Task) error {
+	fs.mutex.Lock()
+	defer fs.mutex.Unlock()
+
+	saved := *task
+	previous, existed := fs.tasks[task.ID]
+	fs.tasks[task.ID] = &saved
+
+	if err := fs.write(); err != nil {
+		if existed {
+			fs.tasks[task.ID] = previous
+		} else {
+			delete(fs.tasks, task.ID)
+		}
+		return err
+	}
+	return nil
+}
+
+// Load returns a copy of the task with the given ID.
+func (fs *JSONFileStore) Load(id int) (*models.Task, error) {
+	fs.mutex.RLock()
+	defer fs.mutex.RUnlock()
+
+	task, exists := fs.tasks[id]
+	if !exists {
+		return nil, ErrNotFound
+	}
+	loaded := *task
+	return &loaded, nil
+}
+
+// Delete removes the task with the given ID.
+func (fs *JSONFileStore) Delete(id int) error {
+	fs.mutex.Lock()
+	defer fs.mutex.Unlock()
+
+	previous, existed := fs.tasks[id]
+	if !existed {
+		return nil
+	}
+	delete(fs.tasks, id)
+
+	if err := fs.write(); err != nil {
+		fs.tasks[id] = previous
+		return err
+	}
+	return nil
+}
+
+// All returns copies of every stored task, ordered by ID.
+func (fs *JSONFileStore) All() ([]*models.Task, error) {
+	fs.mutex.RLock()
+	defer fs.mutex.RUnlock()
+
+	return fs.sorted(), nil
+}
+
+// Ping checks that the store's directory still accepts writes by creating,
+// syncing and removing a small temporary file. A full disk or read-only
+// filesystem is reported before task writes start failing.
+func (fs *JSONFileStore) Ping(ctx context.Context) error {
+	done := make(chan error, 1)
+	go func() {
+		done <- fs.probe()
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Helper methods.
+
+// probe writes, syncs and removes a temporary file next to the store.
+func (fs *JSONFileStore) probe() error {
+	tmp, err := os.CreateTemp(filepath.Dir(fs.path), filepath.Base(fs.path)+".*.ping")
+	if err != nil {
+		return fmt.Errorf("task store is not writable: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write([]byte("ping\n")); err != nil {
+		tmp.Close()
+		return fmt.Errorf("task store is not writable: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("task store is not writable: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("task store is not writable: %w", err)
+	}
+	return nil
+}
+
+// sorted returns copies of the stored tasks ordered by ID. The caller must
+// hold the lock.
+func (fs *JSONFileStore) sorted() []*models.Task {
+	tasks := make([]*models.Task, 0, len(fs.tasks))
+	for _, task := range fs.tasks {
+		copied := *task
+		tasks = append(tasks, &copied)
+	}
+	sort.Slice(tasks, func(i, j int) bool { return tasks[i].ID < tasks[j].ID })
+	return tasks
+}
+
+// write replaces the file with the current tasks, writing to a temporary
+// file in the same directory and renaming it into place. The caller must
+// hold the write lock.
+func (fs *JSONFileStore) write() error {
+	tasks := fs.sorted()
+	if fs.cipher != nil {
+		for _, task := range tasks {
+			if err := fs.cipher.encryptTask(task); err != nil {
+				return err
+			}
+		}
+	}
+
+	data, err := json.MarshalIndent(tasks, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode tasks: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(fs.path), filepath.Base(fs.path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write task store: %w", err)
+	}
+	defer os.Remove(tmp.Name()) // No-op once renamed.
+
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write task store: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write task store: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write task store: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), fs.path); err != nil {
+		return fmt.Errorf("failed to write task store: %w", err)
+	}
+	return nil
+}
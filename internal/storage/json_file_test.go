@@ -0,0 +1,114 @@
+package storage
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"merge-queue/internal/models"
+)
+
+var testKey = []byte("0123456789abcdef0123456789abcdef")
+
+func TestEncryptedStoreRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tasks.json")
+
+	store, err := NewEncryptedJSONFileStore(path, testKey)
+	if err != nil {
+		t.Fatalf("NewEncryptedJSONFileStore() error = %v", err)
+	}
+	task := &models.Task{
+		ID:          1,
+		Title:       "Rotate credentials",
+		Description: "secret plan",
+		Metadata:    map[string]string{"ticket": "SEC-42"},
+	}
+	if err := store.Save(task); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if task.Description != "secret plan" || task.Metadata["ticket"] != "SEC-42" {
+		t.Fatalf("Save() changed the caller's task: %+v", task)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	for _, plaintext := range []string{"secret plan", "SEC-42"} {
+		if bytes.Contains(data, []byte(plaintext)) {
+			t.Errorf("store file contains %q in plaintext", plaintext)
+		}
+	}
+	if !bytes.Contains(data, []byte("Rotate credentials")) {
+		t.Error("store file does not contain the plaintext title")
+	}
+
+	reopened, err := NewEncryptedJSONFileStore(path, testKey)
+	if err != nil {
+		t.Fatalf("reopening error = %v", err)
+	}
+	loaded, err := reopened.Load(1)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if loaded.Description != "secret plan" || loaded.Metadata["ticket"] != "SEC-42" {
+		t.Fatalf("Load() = %+v, want the decrypted fields", loaded)
+	}
+}
+
+func TestEncryptedStoreWrongOrMissingKey(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tasks.json")
+
+	store, err := NewEncryptedJSONFileStore(path, testKey)
+	if err != nil {
+		t.Fatalf("NewEncryptedJSONFileStore() error = %v", err)
+	}
+	if err := store.Save(&models.Task{ID: 1, Title: "A", Description: "hidden"}); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	if _, err := NewJSONFileStore(path); !errors.Is(err, ErrEncryptedStore) {
+		t.Errorf("NewJSONFileStore() error = %v, want ErrEncryptedStore", err)
+	}
+
+	otherKey := []byte(strings.Repeat("k", 32))
+	if _, err := NewEncryptedJSONFileStore(path, otherKey); err == nil {
+		t.Error("NewEncryptedJSONFileStore() with another key error = nil, want an error")
+	}
+}
+
+func TestEncryptedStoreReadsPlaintext(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tasks.json")
+
+	plain, err := NewJSONFileStore(path)
+	if err != nil {
+		t.Fatalf("NewJSONFileStore() error = %v", err)
+	}
+	if err := plain.Save(&models.Task{ID: 1, Title: "A", Description: "legacy"}); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	store, err := NewEncryptedJSONFileStore(path, testKey)
+	if err != nil {
+		t.Fatalf("NewEncryptedJSONFileStore() on a plaintext file error = %v", err)
+	}
+	loaded, err := store.Load(1)
+	if err != nil || loaded.Description != "legacy" {
+		t.Fatalf("Load() = %+v, %v, want the plaintext description", loaded, err)
+	}
+
+	// The next write encrypts the existing task too.
+	if err := store.Save(&models.Task{ID: 2, Title: "B"}); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if bytes.Contains(data, []byte("legacy")) {
+		t.Error("store file still contains the plaintext description after a write")
+	}
+}
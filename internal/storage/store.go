@@ -0,0 +1,34 @@
+package storage
+
+import (
+	"context"
+	"errors"
+
+	"merge-queue/internal/models"
+)
+
+// ErrNotFound is returned by Load for a task ID the store does not hold.
+var ErrNotFound = errors.New("task not found in store")
+
+// TaskStore persists tasks between restarts. Implementations must be safe
+// for concurrent use; TaskService additionally serializes writes under its
+// own lock.
+type TaskStore interface {
+	// Save inserts or replaces the task with task.ID.
+	Save(task *models.Task) error
+	// Load returns the task with the given ID, or ErrNotFound.
+	Load(id int) (*models.Task, error)
+	// Delete removes the task with the given ID. Deleting an unknown ID is
+	// not an error.
+	Delete(id int) error
+	// All returns every stored task, ordered by ID.
+	All() ([]*models.Task, error)
+}
+
+// Pinger is implemented by stores that can check they still accept writes,
+// for readiness probes.
+type Pinger interface {
+	// Ping makes a cheap write and reports any error, or ctx's error if it
+	// does not finish in time.
+	Ping(ctx context.Context) error
+}
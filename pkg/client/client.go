@@ -0,0 +1,293 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Client is a typed HTTP client for the Task Manager API.
+type Client struct {
+	baseURL    string
+	token      string
+	httpClient *http.Client
+}
+
+// APIError is returned when the API responds with an unsuccessful status.
+type APIError struct {
+	StatusCode int
+	Message    string
+}
+
+// Error implements the error interface.
+func (e *APIError) Error() string {
+	return fmt.Sprintf("api error (%d): %s", e.StatusCode, e.Message)
+}
+
+// NewClient creates a client for the API rooted at baseURL, for example
+// "http://localhost:8080/api/v1". token may be empty.
+func NewClient(baseURL, token string) *Client {
+	return &Client{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		token:      token,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// SetHTTPClient replaces the underlying HTTP client.
+func (c *Client) SetHTTPClient(httpClient *http.Client) {
+	c.httpClient = httpClient
+}
+
+// GetTasks returns every task matching the filter, following next_cursor
+// across pages. filter.Limit sets the page size.
+func (c *Client) GetTasks(ctx context.Context, filter *TaskFilter) ([]*Task, error) {
+	page := TaskFilter{}
+	if filter != nil {
+		page = *filter
+	}
+
+	var tasks []*Task
+	for {
+		result, err := c.ListTasks(ctx, &page)
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, result.Tasks...)
+		if result.NextCursor == "" {
+			return tasks, nil
+		}
+		if result.NextCursor == page.Cursor {
+			return nil, fmt.Errorf("server returned the same cursor twice: %s", page.Cursor)
+		}
+
+		// The cursor already accounts for the offset of the first page.
+		page.Cursor = result.NextCursor
+		page.Offset = 0
+	}
+}
+
+// ListTasks returns one page of the tasks matching the filter. Pass the
+// page's NextCursor as filter.Cursor to fetch the next one.
+func (c *Client) ListTasks(ctx context.Context, filter *TaskFilter) (*TaskPage, error) {
+	var data struct {
+		Tasks      []*Task `json:"tasks"`
+		NextCursor string  `json:"next_cursor"`
+		Pagination struct {
+			Total int `json:"total"`
+		} `json:"pagination"`
+	}
+	query, err := filterQuery(filter)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.do(ctx, http.MethodGet, "/tasks"+query, nil, &data); err != nil {
+		return nil, err
+	}
+	return &TaskPage{
+		Tasks:      data.Tasks,
+		Total:      data.Pagination.Total,
+		NextCursor: data.NextCursor,
+	}, nil
+}
+
+// CountTasks returns the number of tasks matching the filter.
+func (c *Client) CountTasks(ctx context.Context, filter *TaskFilter) (int, error) {
+	var data struct {
+		Count int `json:"count"`
+	}
+	query, err := filterQuery(filter)
+	if err != nil {
+		return 0, err
+	}
+	if err := c.do(ctx, http.MethodGet, "/tasks/count"+query, nil, &data); err != nil {
+		return 0, err
+	}
+	return data.Count, nil
+}
+
+// GetTask returns the task with the given ID.
+func (c *Client) GetTask(ctx context.Context, id int) (*Task, error) {
+	var task Task
+	if err := c.do(ctx, http.MethodGet, fmt.Sprintf("/tasks/%d", id), nil, &task); err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
+
+// CreateTask creates a task.
+func (c *Client) CreateTask(ctx context.Context, req *CreateTaskRequest) (*Task, error) {
+	var task Task
+	if err := c.do(ctx, http.MethodPost, "/tasks", req, &task); err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
+
+// UpdateTask applies a partial update to a task.
+func (c *Client) UpdateTask(ctx context.Context, id int, req *UpdateTaskRequest) (*Task, error) {
+	var task Task
+	if err := c.do(ctx, http.MethodPatch, fmt.Sprintf("/tasks/%d", id), req, &task); err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
+
+// ReplaceTask replaces every field of a task. Title, status and priority
+// are required; other fields left out of req are cleared.
+func (c *Client) ReplaceTask(ctx context.Context, id int, req *UpdateTaskRequest) (*Task, error) {
+	var task Task
+	if err := c.do(ctx, http.MethodPut, fmt.Sprintf("/tasks/%d", id), req, &task); err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
+
+// DeleteTask deletes a task.
+func (c *Client) DeleteTask(ctx context.Context, id int) error {
+	return c.do(ctx, http.MethodDelete, fmt.Sprintf("/tasks/%d", id), nil, nil)
+}
+
+// SearchTasks runs a search query.
+func (c *Client) SearchTasks(ctx context.Context, query *SearchQuery) ([]*Task, error) {
+	var data struct {
+		Tasks []*Task `json:"tasks"`
+	}
+	if err := c.do(ctx, http.MethodPost, "/tasks/search", query, &data); err != nil {
+		return nil, err
+	}
+	return data.Tasks, nil
+}
+
+// GetTaskStats returns task statistics.
+func (c *Client) GetTaskStats(ctx context.Context) (*TaskStats, error) {
+	var stats TaskStats
+	if err := c.do(ctx, http.MethodGet, "/tasks/stats", nil, &stats); err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
+
+// Helper methods.
+
+// do sends a request and decodes the APIResponse data into out, which may
+// be nil when no body is expected.
+func (c *Client) do(ctx context.Context, method, path string, body, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to encode request: %w", err)
+		}
+		reader = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+
+	var apiResp struct {
+		Success bool            `json:"success"`
+		Data    json.RawMessage `json:"data"`
+		Error   string          `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&apiResp); err != nil {
+		if resp.StatusCode >= 400 {
+			return &APIError{StatusCode: resp.StatusCode, Message: http.StatusText(resp.StatusCode)}
+		}
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	if resp.StatusCode >= 400 || !apiResp.Success {
+		return &APIError{StatusCode: resp.StatusCode, Message: apiResp.Error}
+	}
+
+	if out == nil || len(apiResp.Data) == 0 {
+		return nil
+	}
+	return json.Unmarshal(apiResp.Data, out)
+}
+
+// filterQuery encodes a filter as listing query parameters. Listings
+// filter on at most one tag, so more than one is an error rather than a
+// silently wider result.
+func filterQuery(filter *TaskFilter) (string, error) {
+	if filter == nil {
+		return "", nil
+	}
+	if len(filter.Tags) > 1 {
+		return "", fmt.Errorf("listing filters support one tag, got %d", len(filter.Tags))
+	}
+
+	values := url.Values{}
+	setIfNotEmpty := func(key, value string) {
+		if value != "" {
+			values.Set(key, value)
+		}
+	}
+
+	setIfNotEmpty("status", filter.Status)
+	setIfNotEmpty("priority", filter.Priority)
+	setIfNotEmpty("assigned_to", filter.AssignedTo)
+	setIfNotEmpty("min_priority", filter.MinPriority)
+	setIfNotEmpty("meta_key", filter.MetaKey)
+	setIfNotEmpty("meta_value", filter.MetaValue)
+	setIfNotEmpty("created_in", filter.CreatedIn)
+	setIfNotEmpty("created_in_week", filter.CreatedInWeek)
+	setIfNotEmpty("age", filter.Age)
+	if filter.DueAfter != nil {
+		values.Set("due_after", filter.DueAfter.Format(time.RFC3339Nano))
+	}
+	if filter.DueBefore != nil {
+		values.Set("due_before", filter.DueBefore.Format(time.RFC3339Nano))
+	}
+	if filter.HasDueDate != nil {
+		values.Set("has_due_date", strconv.FormatBool(*filter.HasDueDate))
+	}
+	if filter.IncludeCompleted != nil {
+		values.Set("include_completed", strconv.FormatBool(*filter.IncludeCompleted))
+	}
+	if len(filter.Tags) > 0 {
+		values.Set("tags", filter.Tags[0])
+	}
+	for key, value := range filter.Metadata {
+		values.Set("metadata."+key, value)
+	}
+	if filter.Limit > 0 {
+		values.Set("limit", strconv.Itoa(filter.Limit))
+	}
+	setIfNotEmpty("cursor", filter.Cursor)
+	if filter.Offset > 0 {
+		values.Set("offset", strconv.Itoa(filter.Offset))
+	}
+
+	if len(values) == 0 {
+		return "", nil
+	}
+	return "?" + values.Encode(), nil
+}
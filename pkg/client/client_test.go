@@ -0,0 +1,219 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+
+	"merge-queue/internal/config"
+	"merge-queue/internal/handlers"
+	"merge-queue/internal/services"
+	"merge-queue/pkg/utils"
+)
+
+// newTestServer serves the task routes from a real TaskService with no
+// sample data and the given default page size.
+func newTestServer(t *testing.T, pageSize int) *httptest.Server {
+	t.Helper()
+
+	cfg, err := config.LoadConfig("")
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	cfg.Features.SeedSampleData = false
+	cfg.Defaults.PageSize = pageSize
+
+	logger := utils.NewLogger(utils.ErrorLevel)
+	th := handlers.NewTaskHandler(services.NewTaskService(cfg), logger)
+
+	router := mux.NewRouter()
+	router.HandleFunc("/tasks", th.GetTasks).Methods("GET")
+	router.HandleFunc("/tasks", th.CreateTask).Methods("POST")
+	router.HandleFunc("/tasks/count", th.CountTasks).Methods("GET")
+	router.HandleFunc("/tasks/{id:[0-9]+}", th.GetTask).Methods("GET")
+	router.HandleFunc("/tasks/{id:[0-9]+}", th.UpdateTask).Methods("PUT")
+	router.HandleFunc("/tasks/{id:[0-9]+}", th.PatchTask).Methods("PATCH")
+	router.HandleFunc("/tasks/{id:[0-9]+}", th.DeleteTask).Methods("DELETE")
+
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestClientTaskLifecycle(t *testing.T) {
+	server := newTestServer(t, 20)
+	c := NewClient(server.URL, "")
+	ctx := context.Background()
+
+	created, err := c.CreateTask(ctx, &CreateTaskRequest{Title: "Write tests", Tags: []string{"qa"}})
+	if err != nil {
+		t.Fatalf("CreateTask() error = %v", err)
+	}
+	if created.ID == 0 || created.Status != "pending" {
+		t.Fatalf("CreateTask() = %+v, want an ID and pending status", created)
+	}
+
+	status := "in-progress"
+	updated, err := c.UpdateTask(ctx, created.ID, &UpdateTaskRequest{Status: &status})
+	if err != nil {
+		t.Fatalf("UpdateTask() error = %v", err)
+	}
+	if updated.Status != status || updated.Title != "Write tests" {
+		t.Fatalf("UpdateTask() = %+v, want status %q and the title kept", updated, status)
+	}
+
+	got, err := c.GetTask(ctx, created.ID)
+	if err != nil {
+		t.Fatalf("GetTask() error = %v", err)
+	}
+	if got.Status != status {
+		t.Fatalf("GetTask() status = %q, want %q", got.Status, status)
+	}
+
+	if err := c.DeleteTask(ctx, created.ID); err != nil {
+		t.Fatalf("DeleteTask() error = %v", err)
+	}
+
+	_, err = c.GetTask(ctx, created.ID)
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNotFound {
+		t.Fatalf("GetTask() after delete error = %v, want a 404 APIError", err)
+	}
+}
+
+func TestClientUpdateClearsTagsAndMetadata(t *testing.T) {
+	server := newTestServer(t, 20)
+	c := NewClient(server.URL, "")
+	ctx := context.Background()
+
+	created, err := c.CreateTask(ctx, &CreateTaskRequest{
+		Title:    "Tidy backlog",
+		Tags:     []string{"ops"},
+		Metadata: map[string]string{"sprint": "23"},
+	})
+	if err != nil {
+		t.Fatalf("CreateTask() error = %v", err)
+	}
+
+	// Nil tags and metadata leave both unchanged.
+	title := "Tidy the backlog"
+	updated, err := c.UpdateTask(ctx, created.ID, &UpdateTaskRequest{Title: &title})
+	if err != nil {
+		t.Fatalf("UpdateTask() error = %v", err)
+	}
+	if len(updated.Tags) != 1 || updated.Metadata["sprint"] != "23" {
+		t.Fatalf("UpdateTask() with nil tags and metadata = %+v, want both kept", updated)
+	}
+
+	updated, err = c.UpdateTask(ctx, created.ID, &UpdateTaskRequest{
+		Tags:     []string{},
+		Metadata: map[string]string{},
+	})
+	if err != nil {
+		t.Fatalf("UpdateTask() error = %v", err)
+	}
+	if len(updated.Tags) != 0 || len(updated.Metadata) != 0 {
+		t.Fatalf("UpdateTask() with empty tags and metadata = %+v, want both cleared", updated)
+	}
+}
+
+func TestClientGetTasksFollowsCursor(t *testing.T) {
+	server := newTestServer(t, 3)
+	c := NewClient(server.URL, "")
+	ctx := context.Background()
+
+	for i := 0; i < 7; i++ {
+		if _, err := c.CreateTask(ctx, &CreateTaskRequest{Title: fmt.Sprintf("Task %d", i)}); err != nil {
+			t.Fatalf("CreateTask(%d) error = %v", i, err)
+		}
+	}
+
+	page, err := c.ListTasks(ctx, nil)
+	if err != nil {
+		t.Fatalf("ListTasks() error = %v", err)
+	}
+	if len(page.Tasks) != 3 || page.Total != 7 || page.NextCursor == "" {
+		t.Fatalf("ListTasks() = %d tasks, total %d, cursor %q; want 3, 7 and a cursor", len(page.Tasks), page.Total, page.NextCursor)
+	}
+
+	tasks, err := c.GetTasks(ctx, nil)
+	if err != nil {
+		t.Fatalf("GetTasks() error = %v", err)
+	}
+	if len(tasks) != 7 {
+		t.Fatalf("GetTasks() returned %d tasks, want 7", len(tasks))
+	}
+	seen := make(map[int]bool)
+	for _, task := range tasks {
+		if seen[task.ID] {
+			t.Fatalf("GetTasks() returned task %d twice", task.ID)
+		}
+		seen[task.ID] = true
+	}
+
+	// An offset applies to the first page only.
+	tasks, err = c.GetTasks(ctx, &TaskFilter{Offset: 2})
+	if err != nil {
+		t.Fatalf("GetTasks(offset) error = %v", err)
+	}
+	if len(tasks) != 5 {
+		t.Fatalf("GetTasks(offset) returned %d tasks, want 5", len(tasks))
+	}
+}
+
+func TestClientAPIError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "Bearer secret" {
+			t.Errorf("Authorization = %q, want %q", got, "Bearer secret")
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{"success": false, "error": "title is required"})
+	}))
+	defer server.Close()
+
+	_, err := NewClient(server.URL, "secret").CreateTask(context.Background(), &CreateTaskRequest{})
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("CreateTask() error = %v, want *APIError", err)
+	}
+	if apiErr.StatusCode != http.StatusBadRequest || apiErr.Message != "title is required" {
+		t.Fatalf("APIError = %+v, want 400 %q", apiErr, "title is required")
+	}
+}
+
+func TestClientListTasksRejectsSeveralTags(t *testing.T) {
+	server := newTestServer(t, 20)
+	c := NewClient(server.URL, "")
+
+	_, err := c.ListTasks(context.Background(), &TaskFilter{Tags: []string{"qa", "ops"}})
+	if err == nil {
+		t.Fatal("ListTasks() with two tags error = nil, want an error")
+	}
+
+	if _, err := c.CountTasks(context.Background(), &TaskFilter{Tags: []string{"qa"}}); err != nil {
+		t.Fatalf("CountTasks() with one tag error = %v", err)
+	}
+}
+
+func TestClientRepeatedCursor(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": true,
+			"data":    map[string]interface{}{"tasks": []Task{{ID: 1}}, "next_cursor": "same"},
+		})
+	}))
+	defer server.Close()
+
+	if _, err := NewClient(server.URL, "").GetTasks(context.Background(), nil); err == nil {
+		t.Fatal("GetTasks() error = nil, want an error for a repeated cursor")
+	}
+}
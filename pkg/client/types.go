@@ -0,0 +1,104 @@
+package client
+
+import "time"
+
+// The types below mirror the API's JSON. They are defined here rather than
+// reused from internal/models so that services outside this module can use
+// the client.
+
+// Task is a task as returned by the API.
+type Task struct {
+	ID                int               `json:"id"`
+	Title             string            `json:"title"`
+	Description       string            `json:"description"`
+	Status            string            `json:"status"`   // "pending", "in-progress", "completed", "cancelled"
+	Priority          string            `json:"priority"` // "low", "medium", "high", "critical"
+	CreatedAt         time.Time         `json:"created_at"`
+	UpdatedAt         time.Time         `json:"updated_at"`
+	CompletedAt       *time.Time        `json:"completed_at,omitempty"`
+	DueDate           *time.Time        `json:"due_date,omitempty"`
+	StatusChangedAt   *time.Time        `json:"status_changed_at,omitempty"`
+	AssigneeChangedAt *time.Time        `json:"assignee_changed_at,omitempty"`
+	AssignedTo        string            `json:"assigned_to,omitempty"`
+	Tags              []string          `json:"tags,omitempty"`
+	Metadata          map[string]string `json:"metadata,omitempty"`
+}
+
+// CreateTaskRequest is the body of a task creation.
+type CreateTaskRequest struct {
+	Title       string            `json:"title"`
+	Description string            `json:"description,omitempty"`
+	Status      string            `json:"status,omitempty"`
+	Priority    string            `json:"priority,omitempty"`
+	AssignedTo  string            `json:"assigned_to,omitempty"`
+	Tags        []string          `json:"tags,omitempty"`
+	DueDate     *time.Time        `json:"due_date,omitempty"`
+	Metadata    map[string]string `json:"metadata,omitempty"`
+}
+
+// UpdateTaskRequest is the body of a task update. Nil fields are left
+// unchanged by UpdateTask. Tags and Metadata are sent even when empty, so
+// a non-nil empty value clears them.
+type UpdateTaskRequest struct {
+	Title       *string           `json:"title,omitempty"`
+	Description *string           `json:"description,omitempty"`
+	Status      *string           `json:"status,omitempty"`
+	Priority    *string           `json:"priority,omitempty"`
+	AssignedTo  *string           `json:"assigned_to,omitempty"`
+	DueDate     *time.Time        `json:"due_date,omitempty"`
+	Tags        []string          `json:"tags"` // Replaces all tags; an empty slice clears them.
+	TagsAdd     []string          `json:"tags_add,omitempty"`
+	TagsRemove  []string          `json:"tags_remove,omitempty"`
+	Metadata    map[string]string `json:"metadata"` // Replaces all metadata; an empty map clears it.
+}
+
+// TaskFilter selects tasks in listings and searches.
+type TaskFilter struct {
+	Status           string            `json:"status,omitempty"`
+	Priority         string            `json:"priority,omitempty"`
+	AssignedTo       string            `json:"assigned_to,omitempty"`
+	MinPriority      string            `json:"min_priority,omitempty"`
+	Tags             []string          `json:"tags,omitempty"` // Listings and counts accept at most one tag; searches accept several.
+	Metadata         map[string]string `json:"metadata,omitempty"`
+	MetaKey          string            `json:"meta_key,omitempty"`
+	MetaValue        string            `json:"meta_value,omitempty"`
+	CreatedIn        string            `json:"created_in,omitempty"`      // Month, e.g. "2024-03".
+	CreatedInWeek    string            `json:"created_in_week,omitempty"` // ISO week, e.g. "2024-W12".
+	Age              string            `json:"age,omitempty"`             // "today", "week" or "older".
+	DueAfter         *time.Time        `json:"due_after,omitempty"`
+	DueBefore        *time.Time        `json:"due_before,omitempty"`
+	HasDueDate       *bool             `json:"has_due_date,omitempty"`
+	IncludeCompleted *bool             `json:"include_completed,omitempty"`
+	Cursor           string            `json:"cursor,omitempty"` // NextCursor from a previous page.
+	Limit            int               `json:"limit,omitempty"`  // Page size; 0 uses the server default.
+	Offset           int               `json:"offset,omitempty"`
+}
+
+// TaskPage is one page of a task listing.
+type TaskPage struct {
+	Tasks      []*Task
+	Total      int    // Tasks matching the filter across all pages.
+	NextCursor string // Cursor for the following page; empty on the last page.
+}
+
+// SearchQuery is the body of a task search.
+type SearchQuery struct {
+	Query        string             `json:"query"`
+	Fields       []string           `json:"fields,omitempty"`        // "title", "description"
+	FieldWeights map[string]float64 `json:"field_weights,omitempty"` // Positive weight per field; missing fields weigh 1.
+	Filters      TaskFilter         `json:"filters"`
+	SortBy       string             `json:"sort_by,omitempty"` // "relevance", "created_at", "updated_at", "priority"
+	SortDesc     bool               `json:"sort_desc,omitempty"`
+}
+
+// TaskStats are aggregate task counts.
+type TaskStats struct {
+	TotalTasks      int            `json:"total_tasks"`
+	TasksByStatus   map[string]int `json:"tasks_by_status"`
+	TasksByPriority map[string]int `json:"tasks_by_priority"`
+	TasksByUser     map[string]int `json:"tasks_by_user"`
+	TasksByTag      map[string]int `json:"tasks_by_tag"`
+	OtherUserTasks  int            `json:"other_user_tasks,omitempty"`
+	OverdueTasks    int            `json:"overdue_tasks"`
+	LastUpdated     time.Time      `json:"last_updated"`
+}
@@ -0,0 +1,95 @@
+package utils
+
+import (
+	"encoding/csv"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"merge-queue/internal/models"
+)
+
+// csvFlushRows is how many rows are written between flushes when streaming.
+const csvFlushRows = 100
+
+// taskCSVHeader lists the columns written by WriteTasksCSV.
+var taskCSVHeader = []string{
+	"id", "title", "description", "status", "priority", "assigned_to",
+	"tags", "created_at", "updated_at", "completed_at", "due_date",
+}
+
+// WriteTasksCSV writes tasks as CSV with a header row. Tags are joined with
+// ";" and timestamps are RFC 3339. If w is an http.Flusher, rows are flushed
+// every csvFlushRows so large results are streamed rather than buffered.
+func WriteTasksCSV(w io.Writer, tasks []*models.Task) error {
+	cw := csv.NewWriter(w)
+	flusher, _ := w.(http.Flusher)
+
+	if err := cw.Write(taskCSVHeader); err != nil {
+		return err
+	}
+
+	for i, task := range tasks {
+		completedAt := ""
+		if task.CompletedAt != nil {
+			completedAt = task.CompletedAt.Format(time.RFC3339)
+		}
+		dueDate := ""
+		if task.DueDate != nil {
+			dueDate = task.DueDate.Format(time.RFC3339)
+		}
+
+		record := []string{
+			strconv.Itoa(task.ID),
+			task.Title,
+			task.Description,
+			task.Status,
+			task.Priority,
+			task.AssignedTo,
+			strings.Join(task.Tags, ";"),
+			task.CreatedAt.Format(time.RFC3339),
+			task.UpdatedAt.Format(time.RFC3339),
+			completedAt,
+			dueDate,
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+
+		if flusher != nil && (i+1)%csvFlushRows == 0 {
+			cw.Flush()
+			if err := cw.Error(); err != nil {
+				return err
+			}
+			flusher.Flush()
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// SendTasksCSV streams tasks to the client as a text/csv response. Once the
+// first row is written the status can no longer change, so write failures
+// are only logged.
+func (rh *ResponseHelper) SendTasksCSV(w http.ResponseWriter, tasks []*models.Task) {
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+
+	if err := WriteTasksCSV(w, tasks); err != nil && rh.logger != nil {
+		rh.logger.Warn("Failed to write CSV response: %v", err)
+	}
+}
+
+// AcceptsCSV reports whether the request's Accept header asks for CSV.
+func AcceptsCSV(r *http.Request) bool {
+	for _, part := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType := strings.TrimSpace(strings.SplitN(part, ";", 2)[0])
+		if strings.EqualFold(mediaType, "text/csv") {
+			return true
+		}
+	}
+	return false
+}
@@ -1,9 +1,13 @@
 package utils
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"log"
 	"os"
+	"sort"
+	"strings"
 	"time"
 )
 
@@ -17,12 +21,34 @@ const (
 	ErrorLevel
 )
 
+// LogFormat selects how a Logger renders each line.
+type LogFormat int
+
+const (
+	// TextFormat writes "[timestamp] LEVEL: message key=value ...".
+	TextFormat LogFormat = iota
+	// JSONFormat writes one JSON object per line with "ts", "level" and
+	// "msg" keys followed by the logger's fields.
+	JSONFormat
+)
+
 // Logger provides structured logging functionality.
 type Logger struct {
 	level  LogLevel
+	format LogFormat
 	logger *log.Logger
+	fields []logField
 }
 
+// logField is a key/value pair attached to every line a Logger writes.
+type logField struct {
+	key   string
+	value interface{}
+}
+
+// loggerContextKey is the context key under which a request Logger is stored.
+type loggerContextKey struct{}
+
 // NewLogger creates a new Logger instance.
 func NewLogger(level LogLevel) *Logger {
 	return &Logger{
@@ -36,6 +62,48 @@ func NewDefaultLogger() *Logger {
 	return NewLogger(InfoLevel)
 }
 
+// With returns a child logger that appends the given key/value field to
+// every message. The parent logger is not modified.
+func (l *Logger) With(key string, value interface{}) *Logger {
+	return l.WithFields(map[string]interface{}{key: value})
+}
+
+// WithFields returns a child logger that appends the given fields to every
+// message, in key order. The parent logger is not modified.
+func (l *Logger) WithFields(fields map[string]interface{}) *Logger {
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	merged := make([]logField, len(l.fields), len(l.fields)+len(keys))
+	copy(merged, l.fields)
+	for _, key := range keys {
+		merged = append(merged, logField{key: key, value: fields[key]})
+	}
+
+	return &Logger{
+		level:  l.level,
+		format: l.format,
+		logger: l.logger,
+		fields: merged,
+	}
+}
+
+// ContextWithLogger returns a copy of ctx carrying the given logger.
+func ContextWithLogger(ctx context.Context, logger *Logger) context.Context {
+	return context.WithValue(ctx, loggerContextKey{}, logger)
+}
+
+// LoggerFromContext returns the logger stored in ctx, or fallback if none.
+func LoggerFromContext(ctx context.Context, fallback *Logger) *Logger {
+	if logger, ok := ctx.Value(loggerContextKey{}).(*Logger); ok && logger != nil {
+		return logger
+	}
+	return fallback
+}
+
 // Debug logs a debug message.
 func (l *Logger) Debug(message string, args ...interface{}) {
 	if l.level <= DebugLevel {
@@ -66,12 +134,88 @@ func (l *Logger) Error(message string, args ...interface{}) {
 
 // log formats and logs a message.
 func (l *Logger) log(level, message string, args ...interface{}) {
-	timestamp := time.Now().Format("2006-01-02 15:04:05")
 	formattedMessage := fmt.Sprintf(message, args...)
+	if l.format == JSONFormat {
+		l.logger.Println(l.jsonLine(level, formattedMessage))
+		return
+	}
+
+	timestamp := time.Now().Format("2006-01-02 15:04:05")
 	logLine := fmt.Sprintf("[%s] %s: %s", timestamp, level, formattedMessage)
+
+	if len(l.fields) > 0 {
+		pairs := make([]string, 0, len(l.fields))
+		for _, field := range l.fields {
+			pairs = append(pairs, fmt.Sprintf("%s=%v", field.key, field.value))
+		}
+		logLine += " " + strings.Join(pairs, " ")
+	}
+
 	l.logger.Println(logLine)
 }
 
+// jsonLine renders a message as a JSON object. Fields keep the order they
+// were attached in; a repeated key takes its latest value, and a field named
+// after a reserved key ("ts", "level", "msg") is written as "fields.<key>".
+func (l *Logger) jsonLine(level, message string) string {
+	var b strings.Builder
+	b.WriteString("{")
+	writeJSONPair(&b, "ts", time.Now().UTC().Format(time.RFC3339Nano))
+	b.WriteString(",")
+	writeJSONPair(&b, "level", strings.ToLower(level))
+	b.WriteString(",")
+	writeJSONPair(&b, "msg", message)
+
+	latest := make(map[string]interface{}, len(l.fields))
+	for _, field := range l.fields {
+		latest[field.key] = field.value
+	}
+	for _, field := range l.fields {
+		value, pending := latest[field.key]
+		if !pending {
+			continue // Already written.
+		}
+		delete(latest, field.key)
+
+		key := field.key
+		if key == "ts" || key == "level" || key == "msg" {
+			key = "fields." + key
+		}
+		b.WriteString(",")
+		writeJSONPair(&b, key, value)
+	}
+
+	b.WriteString("}")
+	return b.String()
+}
+
+// writeJSONPair writes "key":value. Errors are written as their message,
+// and values that cannot be encoded fall back to their %v form.
+func writeJSONPair(b *strings.Builder, key string, value interface{}) {
+	encodedKey, _ := json.Marshal(key)
+	b.Write(encodedKey)
+	b.WriteString(":")
+
+	if err, ok := value.(error); ok {
+		value = err.Error()
+	}
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		encoded, _ = json.Marshal(fmt.Sprintf("%v", value))
+	}
+	b.Write(encoded)
+}
+
+// SetFormat sets the output format.
+func (l *Logger) SetFormat(format LogFormat) {
+	l.format = format
+}
+
+// GetFormat returns the current output format.
+func (l *Logger) GetFormat() LogFormat {
+	return l.format
+}
+
 // SetLevel sets the minimum log level.
 func (l *Logger) SetLevel(level LogLevel) {
 	l.level = level
@@ -97,3 +241,13 @@ func LogLevelFromString(level string) LogLevel {
 		return InfoLevel
 	}
 }
+
+// LogFormatFromString converts a string to LogFormat.
+func LogFormatFromString(format string) LogFormat {
+	switch format {
+	case "json":
+		return JSONFormat
+	default:
+		return TextFormat
+	}
+}
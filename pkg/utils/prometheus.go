@@ -0,0 +1,71 @@
+package utils
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+
+	"merge-queue/internal/models"
+)
+
+// prometheusLabelEscaper escapes label values for the exposition format.
+var prometheusLabelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// WriteTaskStatsMetrics renders stats in the Prometheus text exposition
+// format. Every valid status and priority is written, zero or not, so
+// series do not vanish between scrapes.
+func WriteTaskStatsMetrics(w io.Writer, stats *models.TaskStats) error {
+	var buf bytes.Buffer
+
+	writeGauge := func(name, help, label string, counts map[string]int, always []string) {
+		fmt.Fprintf(&buf, "# HELP %s %s\n", name, help)
+		fmt.Fprintf(&buf, "# TYPE %s gauge\n", name)
+
+		values := make(map[string]int, len(counts)+len(always))
+		for _, key := range always {
+			values[key] = 0
+		}
+		for key, count := range counts {
+			values[key] = count
+		}
+
+		keys := make([]string, 0, len(values))
+		for key := range values {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			fmt.Fprintf(&buf, "%s{%s=\"%s\"} %d\n", name, label, prometheusLabelEscaper.Replace(key), values[key])
+		}
+	}
+
+	writeGauge("tasks_total", "Number of tasks by status.", "status", stats.TasksByStatus, models.GetValidStatuses())
+	writeGauge("tasks_by_priority", "Number of tasks by priority.", "priority", stats.TasksByPriority, models.GetValidPriorities())
+	writeGauge("tasks_by_assignee", "Number of tasks by assignee.", "assignee", stats.TasksByUser, nil)
+	writeGauge("tasks_by_tag", "Number of tasks by tag, lowercased.", "tag", stats.TasksByTag, nil)
+
+	fmt.Fprintf(&buf, "# HELP tasks_other_assignees Assigned tasks left out of tasks_by_assignee.\n")
+	fmt.Fprintf(&buf, "# TYPE tasks_other_assignees gauge\n")
+	fmt.Fprintf(&buf, "tasks_other_assignees %d\n", stats.OtherUserTasks)
+
+	fmt.Fprintf(&buf, "# HELP tasks_overdue Open tasks past their due date.\n")
+	fmt.Fprintf(&buf, "# TYPE tasks_overdue gauge\n")
+	fmt.Fprintf(&buf, "tasks_overdue %d\n", stats.OverdueTasks)
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// SendTaskStatsMetrics sends stats as a Prometheus text response.
+func (rh *ResponseHelper) SendTaskStatsMetrics(w http.ResponseWriter, stats *models.TaskStats) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+
+	if err := WriteTaskStatsMetrics(w, stats); err != nil && rh.logger != nil {
+		rh.logger.Warn("Failed to write metrics response: %v", err)
+	}
+}
@@ -2,39 +2,92 @@ package utils
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
+	"runtime/debug"
+	"strconv"
 	"time"
 
 	"merge-queue/internal/models"
 )
 
+// exposeErrorDetails makes SendInternalError include the underlying error
+// and a stack trace. It is set once at startup by SetExposeErrorDetails.
+var exposeErrorDetails bool
+
+// SetExposeErrorDetails controls whether 500 responses sent through
+// SendInternalError carry the underlying error. Enable it only for local
+// debugging; it must be called before requests are served.
+func SetExposeErrorDetails(enabled bool) {
+	exposeErrorDetails = enabled
+}
+
 // ResponseHelper provides utility functions for HTTP responses.
-type ResponseHelper struct{}
+type ResponseHelper struct {
+	logger *Logger
+}
 
 // NewResponseHelper creates a new ResponseHelper instance.
 func NewResponseHelper() *ResponseHelper {
 	return &ResponseHelper{}
 }
 
-// SendJSON sends a JSON response.
+// NewResponseHelperWithLogger creates a ResponseHelper that logs encoding
+// failures to the given logger.
+func NewResponseHelperWithLogger(logger *Logger) *ResponseHelper {
+	return &ResponseHelper{logger: logger}
+}
+
+// WithRequest returns a copy of the helper that logs through the request's
+// context logger, so error responses carry the request ID. The receiver is
+// not modified.
+func (rh *ResponseHelper) WithRequest(r *http.Request) *ResponseHelper {
+	return &ResponseHelper{logger: LoggerFromContext(r.Context(), rh.logger)}
+}
+
+// SendJSON sends a JSON response. The body is encoded before anything is
+// written, so an encoding failure can still be reported as a 500.
 func (rh *ResponseHelper) SendJSON(w http.ResponseWriter, statusCode int, data interface{}) {
+	body, err := json.Marshal(data)
+	if err != nil {
+		if rh.logger != nil {
+			rh.logger.Error("Failed to encode JSON response: %v", err)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(`{"success":false,"error":"Internal server error"}` + "\n"))
+		return
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(statusCode)
-	json.NewEncoder(w).Encode(data)
+	if _, err := w.Write(append(body, '\n')); err != nil && rh.logger != nil {
+		rh.logger.Warn("Failed to write JSON response: %v", err)
+	}
 }
 
 // SendError sends an error response.
 func (rh *ResponseHelper) SendError(w http.ResponseWriter, statusCode int, message string) {
+	rh.logError(statusCode, message)
+
 	response := models.APIResponse{
 		Success:   false,
 		Error:     message,
-		Timestamp: time.Now(),
+		Timestamp: time.Now().UTC(),
 	}
 	rh.SendJSON(w, statusCode, response)
 }
 
+// SendErrorf sends an error response with a formatted message.
+func (rh *ResponseHelper) SendErrorf(w http.ResponseWriter, statusCode int, format string, args ...interface{}) {
+	rh.SendError(w, statusCode, fmt.Sprintf(format, args...))
+}
+
 // SendErrorWithCode sends an error response with a specific error code.
 func (rh *ResponseHelper) SendErrorWithCode(w http.ResponseWriter, statusCode int, code, message, details string) {
+	rh.logError(statusCode, message)
+
 	errorResp := models.ErrorResponse{
 		Code:    code,
 		Message: message,
@@ -45,18 +98,64 @@ func (rh *ResponseHelper) SendErrorWithCode(w http.ResponseWriter, statusCode in
 		Success:   false,
 		Error:     message,
 		Data:      errorResp,
-		Timestamp: time.Now(),
+		Timestamp: time.Now().UTC(),
+	}
+
+	rh.SendJSON(w, statusCode, response)
+}
+
+// SendInternalError sends a 500 with a generic message and the
+// INTERNAL_ERROR code. When error details are exposed, err and the current
+// stack are included so the cause is visible without reading server logs.
+func (rh *ResponseHelper) SendInternalError(w http.ResponseWriter, message string, err error) {
+	errorResp := models.ErrorResponse{
+		Code:    "INTERNAL_ERROR",
+		Message: message,
+	}
+	if exposeErrorDetails {
+		if err != nil {
+			errorResp.Details = err.Error()
+		}
+		errorResp.Stack = string(debug.Stack())
+	}
+
+	rh.logError(http.StatusInternalServerError, message)
+
+	response := models.APIResponse{
+		Success:   false,
+		Error:     message,
+		Data:      errorResp,
+		Timestamp: time.Now().UTC(),
 	}
+	rh.SendJSON(w, http.StatusInternalServerError, response)
+}
 
+// SendErrorWithData sends an error response with additional data for the
+// client, such as retry hints.
+func (rh *ResponseHelper) SendErrorWithData(w http.ResponseWriter, statusCode int, message string, data interface{}) {
+	rh.logError(statusCode, message)
+
+	response := models.APIResponse{
+		Success:   false,
+		Error:     message,
+		Data:      data,
+		Timestamp: time.Now().UTC(),
+	}
 	rh.SendJSON(w, statusCode, response)
 }
 
+// SetTotalCount sets the X-Total-Count header to the number of items a
+// list-like endpoint matched before paging or truncation.
+func (rh *ResponseHelper) SetTotalCount(w http.ResponseWriter, total int) {
+	w.Header().Set("X-Total-Count", strconv.Itoa(total))
+}
+
 // SendSuccess sends a success response.
 func (rh *ResponseHelper) SendSuccess(w http.ResponseWriter, data interface{}) {
 	response := models.APIResponse{
 		Success:   true,
 		Data:      data,
-		Timestamp: time.Now(),
+		Timestamp: time.Now().UTC(),
 	}
 	rh.SendJSON(w, http.StatusOK, response)
 }
@@ -67,7 +166,7 @@ func (rh *ResponseHelper) SendSuccessWithMeta(w http.ResponseWriter, data interf
 		Success:   true,
 		Data:      data,
 		Meta:      meta,
-		Timestamp: time.Now(),
+		Timestamp: time.Now().UTC(),
 	}
 	rh.SendJSON(w, http.StatusOK, response)
 }
@@ -77,7 +176,7 @@ func (rh *ResponseHelper) SendCreated(w http.ResponseWriter, data interface{}) {
 	response := models.APIResponse{
 		Success:   true,
 		Data:      data,
-		Timestamp: time.Now(),
+		Timestamp: time.Now().UTC(),
 	}
 	rh.SendJSON(w, http.StatusCreated, response)
 }
@@ -100,3 +199,17 @@ func (rh *ResponseHelper) SendPaginated(w http.ResponseWriter, data interface{},
 
 	rh.SendSuccessWithMeta(w, data, meta)
 }
+
+// logError records an error response: server errors at error level,
+// client errors at warn level.
+func (rh *ResponseHelper) logError(statusCode int, message string) {
+	if rh.logger == nil {
+		return
+	}
+
+	if statusCode >= http.StatusInternalServerError {
+		rh.logger.Error("Responding %d: %s", statusCode, message)
+		return
+	}
+	rh.logger.Warn("Responding %d: %s", statusCode, message)
+}
@@ -0,0 +1,43 @@
+package utils
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSendJSONMarshalFailure(t *testing.T) {
+	tests := []struct {
+		name string
+		data interface{}
+	}{
+		{name: "chan", data: make(chan int)},
+		{name: "func", data: map[string]interface{}{"callback": func() {}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			NewResponseHelper().SendJSON(rec, http.StatusOK, tt.data)
+
+			if rec.Code != http.StatusInternalServerError {
+				t.Fatalf("status = %d, want 500", rec.Code)
+			}
+			if got := rec.Header().Get("Content-Type"); got != "application/json" {
+				t.Fatalf("Content-Type = %q, want application/json", got)
+			}
+
+			var body struct {
+				Success bool   `json:"success"`
+				Error   string `json:"error"`
+			}
+			if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+				t.Fatalf("body %q is not JSON: %v", rec.Body.String(), err)
+			}
+			if body.Success || body.Error != "Internal server error" {
+				t.Fatalf("body = %+v, want the internal error envelope", body)
+			}
+		})
+	}
+}
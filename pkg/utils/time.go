@@ -2,6 +2,7 @@ package utils
 
 import (
 	"fmt"
+	"strings"
 	"time"
 )
 
@@ -39,6 +40,16 @@ func (tu *TimeUtils) FormatDuration(d time.Duration) string {
 	return fmt.Sprintf("%d days", days)
 }
 
+// ParseTimestamp parses an RFC 3339 timestamp in any zone and normalizes
+// it to UTC, the zone used for every timestamp the API emits.
+func (tu *TimeUtils) ParseTimestamp(value string) (time.Time, error) {
+	t, err := time.Parse(time.RFC3339Nano, strings.TrimSpace(value))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid timestamp %q: expected RFC 3339 format", value)
+	}
+	return t.UTC(), nil
+}
+
 // IsToday checks if a time is today.
 func (tu *TimeUtils) IsToday(t time.Time) bool {
 	now := time.Now()
@@ -53,11 +64,56 @@ func (tu *TimeUtils) IsThisWeek(t time.Time) bool {
 	return year == tYear && week == tWeek
 }
 
+// ParseMonth parses a "2006-01" month and returns its UTC bounds as a
+// half-open range [start, end).
+func (tu *TimeUtils) ParseMonth(value string) (time.Time, time.Time, error) {
+	start, err := time.Parse("2006-01", strings.TrimSpace(value))
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid month %q: expected YYYY-MM", value)
+	}
+	return start, start.AddDate(0, 1, 0), nil
+}
+
+// ParseISOWeek parses a "2006-W01" ISO 8601 week and returns its UTC
+// bounds, Monday to Monday, as a half-open range [start, end).
+func (tu *TimeUtils) ParseISOWeek(value string) (time.Time, time.Time, error) {
+	invalid := fmt.Errorf("invalid ISO week %q: expected YYYY-Www", value)
+
+	var year, week int
+	value = strings.TrimSpace(value)
+	if len(value) != 8 || value[4:6] != "-W" {
+		return time.Time{}, time.Time{}, invalid
+	}
+	if _, err := fmt.Sscanf(value, "%4d-W%2d", &year, &week); err != nil || week < 1 {
+		return time.Time{}, time.Time{}, invalid
+	}
+
+	// January 4th always falls in week 1.
+	jan4 := time.Date(year, time.January, 4, 0, 0, 0, 0, time.UTC)
+	weekOneMonday := jan4.AddDate(0, 0, -((int(jan4.Weekday()) + 6) % 7))
+	start := weekOneMonday.AddDate(0, 0, (week-1)*7)
+
+	// Reject week 53 in years that only have 52.
+	if y, w := start.ISOWeek(); y != year || w != week {
+		return time.Time{}, time.Time{}, invalid
+	}
+
+	return start, start.AddDate(0, 0, 7), nil
+}
+
 // StartOfDay returns the start of the day for the given time.
 func (tu *TimeUtils) StartOfDay(t time.Time) time.Time {
 	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
 }
 
+// StartOfWeek returns the start of the ISO week (Monday) containing t, in
+// t's location, so [StartOfWeek(t), +7 days) covers the times IsThisWeek
+// accepts when t is now.
+func (tu *TimeUtils) StartOfWeek(t time.Time) time.Time {
+	daysSinceMonday := (int(t.Weekday()) + 6) % 7
+	return tu.StartOfDay(t).AddDate(0, 0, -daysSinceMonday)
+}
+
 // EndOfDay returns the end of the day for the given time.
 func (tu *TimeUtils) EndOfDay(t time.Time) time.Time {
 	return time.Date(t.Year(), t.Month(), t.Day(), 23, 59, 59, 999999999, t.Location())
@@ -2,6 +2,7 @@ package utils
 
 import (
 	"fmt"
+	"regexp"
 	"strings"
 )
 
@@ -76,6 +77,44 @@ func (vu *ValidationUtils) SanitizeString(s string) string {
 	return strings.ToLower(strings.TrimSpace(s))
 }
 
+// ValidateMetadata validates a metadata map's size and key/value lengths.
+func (vu *ValidationUtils) ValidateMetadata(metadata map[string]string, maxKeys, maxKeyLength, maxValueLength int) error {
+	if len(metadata) > maxKeys {
+		return fmt.Errorf("maximum of %d metadata keys allowed", maxKeys)
+	}
+
+	for key, value := range metadata {
+		if strings.TrimSpace(key) == "" {
+			return fmt.Errorf("metadata keys must not be empty")
+		}
+		if len(key) > maxKeyLength {
+			return fmt.Errorf("metadata key '%s' exceeds maximum length of %d characters", key, maxKeyLength)
+		}
+		if len(value) > maxValueLength {
+			return fmt.Errorf("metadata value for '%s' exceeds maximum length of %d characters", key, maxValueLength)
+		}
+	}
+
+	return nil
+}
+
+// ValidateTagPattern checks that every tag, once trimmed, matches pattern in
+// full. A nil pattern allows any tag.
+func (vu *ValidationUtils) ValidateTagPattern(tags []string, pattern *regexp.Regexp) error {
+	if pattern == nil {
+		return nil
+	}
+
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if !pattern.MatchString(tag) {
+			return fmt.Errorf("tag '%s' contains characters that are not allowed", tag)
+		}
+	}
+
+	return nil
+}
+
 // ValidateTagList validates a list of tags.
 func (vu *ValidationUtils) ValidateTagList(tags []string, maxTags int, maxTagLength int) error {
 	if len(tags) > maxTags {